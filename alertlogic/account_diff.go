@@ -0,0 +1,259 @@
+package alertlogic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DiffKind is the kind of a DiffChange.
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// DiffChange is a single difference between two accounts. `Path` names the role, user or
+// deployment and the field that differs, for example
+// `roles["Power User"].permissions["*:own:*:*"]`. `Base` is empty for added values and
+// `Target` is empty for removed values.
+type DiffChange struct {
+	Kind   DiffKind `json:"kind"`
+	Path   string   `json:"path"`
+	Base   string   `json:"base,omitempty"`
+	Target string   `json:"target,omitempty"`
+}
+
+// String returns a human-readable description of the change.
+func (c DiffChange) String() string {
+	switch c.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, c.Target)
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, c.Base)
+	}
+
+	return fmt.Sprintf("~ %s: %s -> %s", c.Path, c.Base, c.Target)
+}
+
+// AccountDiff holds the differences between a base account and a target account. Changes
+// describe what would have to change in the base account to make it match the target account.
+type AccountDiff struct {
+	BaseAccountID   string       `json:"base_account_id"`
+	TargetAccountID string       `json:"target_account_id"`
+	Changes         []DiffChange `json:"changes"`
+}
+
+// Empty reports whether the accounts have no differences.
+func (d AccountDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// String returns a human-readable description of the diff, one change per line.
+func (d AccountDiff) String() string {
+	if d.Empty() {
+		return "no differences"
+	}
+
+	lines := make([]string, len(d.Changes))
+	for i, change := range d.Changes {
+		lines[i] = change.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DiffAccounts compares the configuration of two accounts: custom roles and their permissions,
+// users and their role assignments, and deployments and their scope.
+// Roles and deployments are matched by name and users by email address, since IDs differ between
+// accounts. Role assignments are compared by role name. Global roles are not compared, but
+// assignments of them are.
+func (api *API) DiffAccounts(baseAccountId string, targetAccountId string) (AccountDiff, error) {
+	if baseAccountId == "" || targetAccountId == "" {
+		return AccountDiff{}, errors.New(errEmptyAccountId)
+	}
+
+	base, err := api.forAccount(baseAccountId).accountSnapshot()
+	if err != nil {
+		return AccountDiff{}, errors.Wrapf(err, "error reading account %s", baseAccountId)
+	}
+	target, err := api.forAccount(targetAccountId).accountSnapshot()
+	if err != nil {
+		return AccountDiff{}, errors.Wrapf(err, "error reading account %s", targetAccountId)
+	}
+
+	diff := AccountDiff{BaseAccountID: baseAccountId, TargetAccountID: targetAccountId, Changes: []DiffChange{}}
+	diff.Changes = append(diff.Changes, diffEntities("roles", base.roles, target.roles)...)
+	diff.Changes = append(diff.Changes, diffEntities("users", base.users, target.users)...)
+	diff.Changes = append(diff.Changes, diffEntities("deployments", base.deployments, target.deployments)...)
+
+	return diff, nil
+}
+
+// forAccount returns a copy of the API client that makes requests for `accountId`.
+func (api *API) forAccount(accountId string) *API {
+	c := *api
+	c.AccountID = accountId
+
+	return &c
+}
+
+// accountSnapshot holds an account's roles, users and deployments, each flattened to a map of
+// field paths to values and keyed by name or email address.
+type accountSnapshot struct {
+	roles       map[string]map[string]string
+	users       map[string]map[string]string
+	deployments map[string]map[string]string
+}
+
+// accountSnapshot reads and flattens the account's configuration.
+func (api *API) accountSnapshot() (accountSnapshot, error) {
+	roles, err := api.ListRoles()
+	if err != nil {
+		return accountSnapshot{}, err
+	}
+	users, err := api.ListUsers(false, false, true, "")
+	if err != nil {
+		return accountSnapshot{}, err
+	}
+	deployments, err := api.ListDeployments()
+	if err != nil {
+		return accountSnapshot{}, err
+	}
+
+	snapshot := accountSnapshot{
+		roles:       map[string]map[string]string{},
+		users:       map[string]map[string]string{},
+		deployments: map[string]map[string]string{},
+	}
+
+	roleNames := map[string]string{}
+	for _, role := range roles.Roles {
+		roleNames[role.ID] = role.Name
+		if role.Global {
+			continue
+		}
+
+		fields := map[string]string{}
+		for permission, effect := range role.Permissions {
+			fields[fmt.Sprintf("permissions[%q]", permission)] = string(effect)
+		}
+		snapshot.roles[role.Name] = fields
+	}
+
+	for _, user := range users.Users {
+		fields := map[string]string{
+			"name":               user.Name,
+			"active":             strconv.FormatBool(user.Active),
+			"notifications_only": strconv.FormatBool(user.NotificationsOnly),
+		}
+		if user.MobilePhone != nil {
+			fields["mobile_phone"] = *user.MobilePhone
+		}
+		if user.RoleIds != nil {
+			for _, roleId := range *user.RoleIds {
+				fields[fmt.Sprintf("roles[%q]", roleName(roleNames, roleId))] = "granted"
+			}
+		}
+		snapshot.users[strings.ToLower(user.Email)] = fields
+	}
+
+	for _, deployment := range deployments {
+		fields := map[string]string{
+			"platform.type":          deployment.Platform.Type,
+			"platform.id":            deployment.Platform.ID,
+			"mode":                   deployment.Mode,
+			"enabled":                strconv.FormatBool(deployment.Enabled),
+			"discover":               strconv.FormatBool(deployment.Discover),
+			"scan":                   strconv.FormatBool(deployment.Scan),
+			"cloud_defender.enabled": strconv.FormatBool(deployment.CloudDefender.Enabled),
+		}
+		for _, include := range deployment.Scope.Include {
+			fields[fmt.Sprintf("scope.include[%q].policy.id", include.Type+":"+include.Key)] = include.Policy.ID
+		}
+		for _, exclude := range deployment.Scope.Exclude {
+			fields[fmt.Sprintf("scope.exclude[%q]", exclude.Type+":"+exclude.Key)] = "excluded"
+		}
+		snapshot.deployments[deployment.Name] = fields
+	}
+
+	return snapshot, nil
+}
+
+// diffEntities compares two sets of flattened entities, returning changes sorted by path.
+// Entities that only exist on one side are reported as a single added or removed change.
+func diffEntities(section string, base map[string]map[string]string, target map[string]map[string]string) []DiffChange {
+	changes := []DiffChange{}
+
+	for _, name := range entityNames(base, target) {
+		path := fmt.Sprintf("%s[%q]", section, name)
+		baseFields, inBase := base[name]
+		targetFields, inTarget := target[name]
+
+		switch {
+		case !inBase:
+			changes = append(changes, DiffChange{Kind: DiffAdded, Path: path, Target: describeFields(targetFields)})
+		case !inTarget:
+			changes = append(changes, DiffChange{Kind: DiffRemoved, Path: path, Base: describeFields(baseFields)})
+		default:
+			for _, field := range fieldNames(baseFields, targetFields) {
+				baseValue, inBase := baseFields[field]
+				targetValue, inTarget := targetFields[field]
+				fieldPath := path + "." + field
+
+				switch {
+				case !inBase:
+					changes = append(changes, DiffChange{Kind: DiffAdded, Path: fieldPath, Target: targetValue})
+				case !inTarget:
+					changes = append(changes, DiffChange{Kind: DiffRemoved, Path: fieldPath, Base: baseValue})
+				case baseValue != targetValue:
+					changes = append(changes, DiffChange{Kind: DiffChanged, Path: fieldPath, Base: baseValue, Target: targetValue})
+				}
+			}
+		}
+	}
+
+	return changes
+}
+
+// describeFields summarizes a flattened entity as sorted `field=value` pairs.
+func describeFields(fields map[string]string) string {
+	keys := fieldNames(fields, nil)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + fields[key]
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// entityNames returns the names of the entities in `base` or `target` in sorted order.
+func entityNames(base map[string]map[string]string, target map[string]map[string]string) []string {
+	names := map[string]bool{}
+	for name := range base {
+		names[name] = true
+	}
+	for name := range target {
+		names[name] = true
+	}
+
+	return sortedKeys(names)
+}
+
+// fieldNames returns the fields of `base` or `target` in sorted order.
+func fieldNames(base map[string]string, target map[string]string) []string {
+	fields := map[string]bool{}
+	for field := range base {
+		fields[field] = true
+	}
+	for field := range target {
+		fields[field] = true
+	}
+
+	return sortedKeys(fields)
+}
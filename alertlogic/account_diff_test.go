@@ -0,0 +1,102 @@
+package alertlogic
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// accountDiffHandlers serves the roles, users and deployments of the base and target accounts.
+func accountDiffHandlers() {
+	serve := func(path string, body string) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, body)
+		})
+	}
+
+	serve(fmt.Sprintf("/%s/%s/roles", aimsServicePath, testAccountId), `{"roles": [
+		{"id": "B1", "name": "Read Only", "permissions": {"*:own:get:*": "allowed", "*:own:list:*": "allowed"}},
+		{"id": "B2", "name": "Auditors", "permissions": {"*:own:get:*": "allowed"}},
+		{"id": "G1", "name": "Administrator", "permissions": {"*:*:*:*": "allowed"}, "global": true}
+	]}`)
+	serve(fmt.Sprintf("/%s/%s/roles", aimsServicePath, testRelatedAccountId), `{"roles": [
+		{"id": "T1", "name": "Read Only", "permissions": {"*:own:get:*": "allowed", "*:own:list:*": "denied"}},
+		{"id": "G1", "name": "Administrator", "permissions": {"*:*:*:*": "allowed"}, "global": true}
+	]}`)
+
+	serve(fmt.Sprintf("/%s/%s/users", aimsServicePath, testAccountId), `{"users": [
+		{"id": "U1", "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "active": true, "role_ids": ["B1", "G1"]}
+	]}`)
+	serve(fmt.Sprintf("/%s/%s/users", aimsServicePath, testRelatedAccountId), `{"users": [
+		{"id": "U2", "name": "Bob Loblaw", "email": "Bob@BobLoblawLaw.com", "active": false, "role_ids": ["T1"]},
+		{"id": "U3", "name": "Lucille Bluth", "email": "lucille@bluth.com", "active": true}
+	]}`)
+
+	serve(fmt.Sprintf("/%s/%s/deployments", deploymentServicePath, testAccountId), `[
+		{"id": "D1", "name": "Production", "mode": "automatic", "enabled": true, "platform": {"type": "aws", "id": "111"},
+		 "scope": {"include": [{"type": "vpc", "key": "/aws/us-east-1/vpc/vpc-1", "policy": {"id": "P1"}}]}}
+	]`)
+	serve(fmt.Sprintf("/%s/%s/deployments", deploymentServicePath, testRelatedAccountId), `[
+		{"id": "D2", "name": "Production", "mode": "automatic", "enabled": true, "platform": {"type": "aws", "id": "111"},
+		 "scope": {"include": [{"type": "vpc", "key": "/aws/us-east-1/vpc/vpc-1", "policy": {"id": "P2"}}],
+		           "exclude": [{"type": "subnet", "key": "/aws/us-east-1/subnet/subnet-1"}]}}
+	]`)
+}
+
+func TestAccountDiff_DiffAccounts(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountDiffHandlers()
+
+	diff, err := client.DiffAccounts(testAccountId, testRelatedAccountId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, testAccountId, diff.BaseAccountID)
+		assert.Equal(t, testRelatedAccountId, diff.TargetAccountID)
+		assert.Equal(t, strings.Join([]string{
+			`- roles["Auditors"]: permissions["*:own:get:*"]=allowed`,
+			`~ roles["Read Only"].permissions["*:own:list:*"]: allowed -> denied`,
+			`~ users["bob@bobloblawlaw.com"].active: true -> false`,
+			`- users["bob@bobloblawlaw.com"].roles["Administrator"]: granted`,
+			`+ users["lucille@bluth.com"]: active=true, name=Lucille Bluth, notifications_only=false`,
+			`+ deployments["Production"].scope.exclude["subnet:/aws/us-east-1/subnet/subnet-1"]: excluded`,
+			`~ deployments["Production"].scope.include["vpc:/aws/us-east-1/vpc/vpc-1"].policy.id: P1 -> P2`,
+		}, "\n"), diff.String())
+		assert.Equal(t, DiffChange{
+			Kind:   DiffChanged,
+			Path:   `roles["Read Only"].permissions["*:own:list:*"]`,
+			Base:   "allowed",
+			Target: "denied",
+		}, diff.Changes[1])
+	}
+}
+
+func TestAccountDiff_DiffAccountsSame(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountDiffHandlers()
+
+	diff, err := client.DiffAccounts(testRelatedAccountId, testRelatedAccountId)
+
+	if assert.NoError(t, err) {
+		assert.True(t, diff.Empty())
+		assert.Equal(t, "no differences", diff.String())
+	}
+}
+
+func TestAccountDiff_DiffAccountsError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.DiffAccounts(testAccountId, "")
+	assert.EqualError(t, err, errEmptyAccountId)
+
+	_, err = client.DiffAccounts(testAccountId, testRelatedAccountId)
+	assert.Contains(t, err.Error(), "error reading account 12345678")
+	assert.Equal(t, testAccountId, client.AccountID)
+}
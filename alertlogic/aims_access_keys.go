@@ -0,0 +1,184 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AccessKeyList holds a list of access keys.
+type AccessKeyList struct {
+	AccessKeys []AccessKey `json:"access_keys"`
+}
+
+// CreateAccessKeyRequest holds the access key create request data.
+type CreateAccessKeyRequest struct {
+	Label string `json:"label,omitempty"`
+}
+
+// AccessKeySink receives a newly created access key, including its `SecretKey`, during
+// RotateAccessKey. Returning an error aborts the rotation and the new access key is deleted.
+type AccessKeySink func(accessKey AccessKey) error
+
+// LastUsed returns the time the access key was last used to authenticate. The zero time is
+// returned when the access key has never been used.
+func (k AccessKey) LastUsed() time.Time {
+	if k.LastLogin == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(k.LastLogin), 0)
+}
+
+// RotationDue reports whether the access key was created more than `maxAge` ago. Use Unused to
+// also find access keys that have not authenticated recently.
+func (k AccessKey) RotationDue(maxAge time.Duration) bool {
+	return k.Created.Time().Before(timeNow().Add(-maxAge))
+}
+
+// Unused reports whether the access key has not been used to authenticate for more than
+// `maxIdle`. An access key that has never been used is unused once it is older than `maxIdle`.
+func (k AccessKey) Unused(maxIdle time.Duration) bool {
+	lastUsed := k.LastUsed()
+	if lastUsed.IsZero() {
+		lastUsed = k.Created.Time()
+	}
+
+	return lastUsed.Before(timeNow().Add(-maxIdle))
+}
+
+// ListAccessKeys lists all access keys for a user.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Access_Keys_Resources-ListUserAccessKeys
+func (api *API) ListAccessKeys(userId string) (AccessKeyList, error) {
+	res, _, err := api.makeRequest("GET", fmt.Sprintf("%s/%s/users/%s/access_keys", aimsServicePath, api.AccountID, userId), nil, nil, nil)
+	if err != nil {
+		return AccessKeyList{}, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r AccessKeyList
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return AccessKeyList{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// CreateAccessKey creates a new access key for a user.
+// The returned `AccessKey.SecretKey` is the only time the secret is available, so it must be
+// stored by the caller.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Access_Keys_Resources-CreateUserAccessKey
+func (api *API) CreateAccessKey(userId string, label string) (AccessKey, error) {
+	res, _, err := api.makeRequest("POST", fmt.Sprintf("%s/%s/users/%s/access_keys", aimsServicePath, api.AccountID, userId), nil, nil, CreateAccessKeyRequest{Label: label})
+	if err != nil {
+		return AccessKey{}, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r AccessKey
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return AccessKey{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// DeleteAccessKey deletes an access key belonging to a user.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Access_Keys_Resources-DeleteUserAccessKey
+func (api *API) DeleteAccessKey(userId string, accessKeyId string) (int, error) {
+	_, statusCode, err := api.makeRequest("DELETE", fmt.Sprintf("%s/%s/users/%s/access_keys/%s", aimsServicePath, api.AccountID, userId, accessKeyId), nil, nil, nil)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
+// RotateAccessKey replaces the access key `oldAccessKeyId` belonging to a user with a new one.
+// The new access key is created with `label`, or the old access key's label if `label` is empty,
+// and verified by authenticating a fresh client with it. The new access key is then handed to
+// `sink` and, once `sink` succeeds, the old access key is deleted.
+//
+// If creating, verifying or storing the new access key fails, the new access key is deleted and
+// the old access key is left in place. If deleting the old access key fails, the new access key
+// is kept, since `sink` already holds it, and is returned alongside the error.
+func (api *API) RotateAccessKey(userId string, oldAccessKeyId string, label string, sink AccessKeySink) (AccessKey, error) {
+	if sink == nil {
+		return AccessKey{}, errors.New(errNilAccessKeySink)
+	}
+
+	accessKeys, err := api.ListAccessKeys(userId)
+	if err != nil {
+		return AccessKey{}, err
+	}
+
+	var oldAccessKey *AccessKey
+	for i := range accessKeys.AccessKeys {
+		if accessKeys.AccessKeys[i].AccessKeyID == oldAccessKeyId {
+			oldAccessKey = &accessKeys.AccessKeys[i]
+			break
+		}
+	}
+	if oldAccessKey == nil {
+		return AccessKey{}, errors.Errorf("access key %s not found for user %s", oldAccessKeyId, userId)
+	}
+
+	if label == "" {
+		label = oldAccessKey.Label
+	}
+
+	newAccessKey, err := api.CreateAccessKey(userId, label)
+	if err != nil {
+		return AccessKey{}, errors.Wrap(err, "error creating new access key")
+	}
+
+	if err := api.verifyAccessKey(newAccessKey.AccessKeyID, newAccessKey.SecretKey); err != nil {
+		return AccessKey{}, api.rollbackAccessKey(userId, newAccessKey.AccessKeyID, errors.Wrap(err, "error verifying new access key"))
+	}
+
+	if err := sink(newAccessKey); err != nil {
+		return AccessKey{}, api.rollbackAccessKey(userId, newAccessKey.AccessKeyID, errors.Wrap(err, "error storing new access key"))
+	}
+
+	if _, err := api.DeleteAccessKey(userId, oldAccessKeyId); err != nil {
+		return newAccessKey, errors.Wrapf(err, "new access key %s is in place but the old access key %s could not be deleted", newAccessKey.AccessKeyID, oldAccessKeyId)
+	}
+
+	return newAccessKey, nil
+}
+
+// verifyAccessKey checks that an access key can authenticate against the same API as `api`.
+func (api *API) verifyAccessKey(accessKeyId string, secretKey string) error {
+	if accessKeyId == "" || secretKey == "" {
+		return errors.New(errEmptyAccessKeyIdOrSecretKey)
+	}
+
+	verifier := &API{
+		BaseURL:    api.BaseURL,
+		AccountID:  api.AccountID,
+		Username:   accessKeyId,
+		Password:   secretKey,
+		headers:    make(http.Header),
+		httpClient: api.httpClient,
+	}
+
+	_, err := verifier.Authenticate()
+	return err
+}
+
+// rollbackAccessKey deletes a newly created access key after a failed rotation step and returns
+// `cause`, annotated if the rollback itself failed.
+func (api *API) rollbackAccessKey(userId string, accessKeyId string, cause error) error {
+	if _, err := api.DeleteAccessKey(userId, accessKeyId); err != nil {
+		return errors.Wrapf(cause, "rollback failed, access key %s was not deleted: %v", accessKeyId, err)
+	}
+
+	return cause
+}
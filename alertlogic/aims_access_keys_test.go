@@ -0,0 +1,306 @@
+package alertlogic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAccessKeyId    = "61fb235617960503"
+	testNewAccessKeyId = "0ba4c2a1d26c2f85"
+	testNewSecretKey   = "new_secret_key"
+)
+
+var (
+	accessKeysPath      = fmt.Sprintf("/%s/%s/users/%s/access_keys", aimsServicePath, testAccountId, testUserId)
+	oldAccessKeyPath    = fmt.Sprintf("/%s/%s/users/%s/access_keys/%s", aimsServicePath, testAccountId, testUserId, testAccessKeyId)
+	newAccessKeyPath    = fmt.Sprintf("/%s/%s/users/%s/access_keys/%s", aimsServicePath, testAccountId, testUserId, testNewAccessKeyId)
+	listAccessKeysReply = `
+	{
+		"access_keys": [{
+			"label": "automation",
+			"last_login": 1525410990,
+			"created": {
+				"at": 1525410880,
+				"by": "System"
+			},
+			"modified": {
+				"at": 1525410880,
+				"by": "System"
+			},
+			"access_key_id": "61fb235617960503"
+		}]
+	}`
+	createAccessKeyReply = `
+	{
+		"label": "automation",
+		"last_login": 0,
+		"created": {
+			"at": 1634567890,
+			"by": "System"
+		},
+		"modified": {
+			"at": 1634567890,
+			"by": "System"
+		},
+		"access_key_id": "0ba4c2a1d26c2f85",
+		"secret_key": "new_secret_key"
+	}`
+)
+
+func TestAims_ListAccessKeys(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(accessKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, listAccessKeysReply)
+	})
+
+	want := AccessKeyList{
+		AccessKeys: []AccessKey{
+			{
+				Label:       "automation",
+				LastLogin:   1525410990,
+				Created:     ModifiedCreated{At: 1525410880, By: "System"},
+				Modified:    ModifiedCreated{At: 1525410880, By: "System"},
+				AccessKeyID: testAccessKeyId,
+			},
+		},
+	}
+
+	accessKeys, err := client.ListAccessKeys(testUserId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, accessKeys, want)
+	}
+}
+
+func TestAims_CreateAccessKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(accessKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"label": "automation"}`, string(body))
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, createAccessKeyReply)
+	})
+
+	want := AccessKey{
+		Label:       "automation",
+		Created:     ModifiedCreated{At: 1634567890, By: "System"},
+		Modified:    ModifiedCreated{At: 1634567890, By: "System"},
+		AccessKeyID: testNewAccessKeyId,
+		SecretKey:   testNewSecretKey,
+	}
+
+	accessKey, err := client.CreateAccessKey(testUserId, "automation")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, accessKey, want)
+	}
+}
+
+func TestAims_DeleteAccessKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(oldAccessKeyPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	deleteResponse, err := client.DeleteAccessKey(testUserId, testAccessKeyId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, deleteResponse, http.StatusNoContent)
+	}
+}
+
+func TestAims_DeleteAccessKeyError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(oldAccessKeyPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	respCode, err := client.DeleteAccessKey(testUserId, testAccessKeyId)
+
+	assert.Error(t, err)
+	assert.Equal(t, respCode, http.StatusNotFound)
+	assert.Equal(t, err.Error(), testNotFoundError)
+}
+
+func TestAims_AccessKeyRotationDue(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(1525410880, 0).Add(100 * 24 * time.Hour) }
+
+	accessKey := AccessKey{Created: ModifiedCreated{At: 1525410880}}
+
+	assert.True(t, accessKey.RotationDue(90*24*time.Hour))
+	assert.False(t, accessKey.RotationDue(120*24*time.Hour))
+	assert.True(t, accessKey.LastUsed().IsZero())
+}
+
+func TestAims_AccessKeyUnused(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(1525410880, 0).Add(100 * 24 * time.Hour) }
+
+	neverUsed := AccessKey{Created: ModifiedCreated{At: 1525410880}}
+	assert.True(t, neverUsed.Unused(30*24*time.Hour))
+	assert.False(t, neverUsed.Unused(120*24*time.Hour))
+
+	recentlyUsed := AccessKey{Created: ModifiedCreated{At: 1525410880}, LastLogin: 1525410880 + 95*24*60*60}
+	assert.False(t, recentlyUsed.Unused(30*24*time.Hour))
+	assert.True(t, recentlyUsed.Unused(24*time.Hour))
+}
+
+// rotationHandlers registers handlers for a full access key rotation and records which access
+// keys were deleted.
+func rotationHandlers(t *testing.T, authStatus int, deleteOldStatus int) *[]string {
+	deleted := []string{}
+
+	mux.HandleFunc(accessKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, listAccessKeysReply)
+		case "POST":
+			fmt.Fprint(w, createAccessKeyReply)
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+	})
+
+	mux.HandleFunc(authenticatePath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, testNewAccessKeyId, username)
+		assert.Equal(t, testNewSecretKey, password)
+		assert.Empty(t, r.Header.Get("X-Aims-Auth-Token"))
+
+		w.WriteHeader(authStatus)
+		if authStatus == http.StatusOK {
+			fmt.Fprint(w, `{"authentication": {"token": "new_token"}}`)
+		}
+	})
+
+	mux.HandleFunc(oldAccessKeyPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(deleteOldStatus)
+		if deleteOldStatus == http.StatusNoContent {
+			deleted = append(deleted, testAccessKeyId)
+		}
+	})
+
+	mux.HandleFunc(newAccessKeyPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+		deleted = append(deleted, testNewAccessKeyId)
+	})
+
+	return &deleted
+}
+
+func TestAims_RotateAccessKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deleted := rotationHandlers(t, http.StatusOK, http.StatusNoContent)
+
+	var stored AccessKey
+	accessKey, err := client.RotateAccessKey(testUserId, testAccessKeyId, "", func(k AccessKey) error {
+		stored = k
+		return nil
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, testNewAccessKeyId, accessKey.AccessKeyID)
+		assert.Equal(t, testNewSecretKey, stored.SecretKey)
+		assert.Equal(t, []string{testAccessKeyId}, *deleted)
+	}
+}
+
+func TestAims_RotateAccessKeyVerificationFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deleted := rotationHandlers(t, http.StatusUnauthorized, http.StatusNoContent)
+
+	sinkCalled := false
+	_, err := client.RotateAccessKey(testUserId, testAccessKeyId, "automation", func(k AccessKey) error {
+		sinkCalled = true
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.False(t, sinkCalled)
+	assert.Equal(t, []string{testNewAccessKeyId}, *deleted)
+}
+
+func TestAims_RotateAccessKeySinkFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deleted := rotationHandlers(t, http.StatusOK, http.StatusNoContent)
+
+	_, err := client.RotateAccessKey(testUserId, testAccessKeyId, "", func(k AccessKey) error {
+		return errors.New("vault unavailable")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), "error storing new access key: vault unavailable")
+	assert.Equal(t, []string{testNewAccessKeyId}, *deleted)
+}
+
+func TestAims_RotateAccessKeyDeleteOldFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deleted := rotationHandlers(t, http.StatusOK, http.StatusInternalServerError)
+
+	accessKey, err := client.RotateAccessKey(testUserId, testAccessKeyId, "", func(k AccessKey) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, testNewAccessKeyId, accessKey.AccessKeyID)
+	assert.Empty(t, *deleted)
+}
+
+func TestAims_RotateAccessKeyUnknownKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	rotationHandlers(t, http.StatusOK, http.StatusNoContent)
+
+	_, err := client.RotateAccessKey(testUserId, "unknown", "", func(k AccessKey) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), fmt.Sprintf("access key unknown not found for user %s", testUserId))
+}
+
+func TestAims_RotateAccessKeyNilSink(t *testing.T) {
+	_, err := client.RotateAccessKey(testUserId, testAccessKeyId, "", nil)
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errNilAccessKeySink)
+}
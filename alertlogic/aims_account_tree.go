@@ -0,0 +1,126 @@
+package alertlogic
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// defaultAccountTreeConcurrency is the number of concurrent requests WalkAccountTree makes when
+// no concurrency is given.
+const defaultAccountTreeConcurrency = 4
+
+// AccountNode is an account and the accounts it manages.
+type AccountNode struct {
+	Account  AccountDetails `json:"account"`
+	Children []*AccountNode `json:"children,omitempty"`
+}
+
+// Walk calls `fn` for the node and every node below it, depth first, with the node's depth
+// relative to `n`. Walking stops at the first error returned by `fn`.
+func (n *AccountNode) Walk(fn func(node *AccountNode, depth int) error) error {
+	return n.walk(fn, 0)
+}
+
+// walk holds the recursive logic for Walk.
+func (n *AccountNode) walk(fn func(node *AccountNode, depth int) error, depth int) error {
+	if err := fn(n, depth); err != nil {
+		return err
+	}
+
+	for _, child := range n.Children {
+		if err := child.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WalkAccountTree builds the hierarchy of managed accounts below the account, following
+// `managed` relationships recursively. At most `maxConcurrency` requests are made at once; a
+// value less than one uses a default of 4.
+// Each account appears in the tree only once, so accounts managed by more than one account are
+// placed under whichever parent is reached first and relationship cycles are not followed.
+func (api *API) WalkAccountTree(maxConcurrency int) (*AccountNode, error) {
+	if maxConcurrency < 1 {
+		maxConcurrency = defaultAccountTreeConcurrency
+	}
+
+	account, err := api.GetAccountDetails()
+	if err != nil {
+		return nil, err
+	}
+
+	root := &AccountNode{Account: account}
+	walker := &accountTreeWalker{
+		api:     api,
+		sem:     make(chan struct{}, maxConcurrency),
+		visited: map[string]bool{account.ID: true},
+	}
+
+	walker.wg.Add(1)
+	go walker.expand(root)
+	walker.wg.Wait()
+
+	if walker.err != nil {
+		return nil, walker.err
+	}
+
+	return root, nil
+}
+
+// accountTreeWalker holds the shared state for WalkAccountTree.
+type accountTreeWalker struct {
+	api     *API
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	visited map[string]bool
+	err     error
+}
+
+// expand lists the accounts managed by `node`, attaches any that have not been visited yet as
+// children and expands them concurrently.
+func (w *accountTreeWalker) expand(node *AccountNode) {
+	defer w.wg.Done()
+
+	if w.failed() {
+		return
+	}
+
+	w.sem <- struct{}{}
+	managed, err := w.api.listAccounts(node.Account.ID, Managed)
+	<-w.sem
+
+	w.mu.Lock()
+	if err != nil {
+		if w.err == nil {
+			w.err = errors.Wrapf(err, "error listing managed accounts for account %s", node.Account.ID)
+		}
+		w.mu.Unlock()
+		return
+	}
+
+	for _, account := range managed.Accounts {
+		if w.visited[account.ID] {
+			continue
+		}
+		w.visited[account.ID] = true
+		node.Children = append(node.Children, &AccountNode{Account: account})
+	}
+	w.mu.Unlock()
+
+	for _, child := range node.Children {
+		w.wg.Add(1)
+		go w.expand(child)
+	}
+}
+
+// failed reports whether an earlier expansion has failed.
+func (w *accountTreeWalker) failed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err != nil
+}
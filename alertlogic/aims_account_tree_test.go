@@ -0,0 +1,155 @@
+package alertlogic
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// accountTreeHandlers serves account details for the test account and the managed accounts
+// for every account in `tree`.
+func accountTreeHandlers(t *testing.T, tree map[string][]string) {
+	mux.HandleFunc(accountDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		fmt.Fprintf(w, `{"id": %q, "name": "Account %s"}`, testAccountId, testAccountId)
+	})
+
+	for accountId, children := range tree {
+		children := children
+		mux.HandleFunc(fmt.Sprintf("/%s/%s/accounts/managed", aimsServicePath, accountId), func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+
+			accounts := AccountsList{Accounts: []AccountDetails{}}
+			for _, child := range children {
+				accounts.Accounts = append(accounts.Accounts, AccountDetails{ID: child, Name: "Account " + child})
+			}
+			writeJSON(t, w, accounts)
+		})
+	}
+}
+
+// collectAccountTree flattens an account tree into "depth:id" entries.
+func collectAccountTree(root *AccountNode) []string {
+	var entries []string
+	_ = root.Walk(func(node *AccountNode, depth int) error {
+		entries = append(entries, fmt.Sprintf("%d:%s", depth, node.Account.ID))
+		return nil
+	})
+	return entries
+}
+
+func TestAims_WalkAccountTree(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountTreeHandlers(t, map[string][]string{
+		testAccountId: {"1", "2"},
+		"1":           {"11", "12"},
+		"2":           {},
+		"11":          {},
+		"12":          {},
+	})
+
+	root, err := client.WalkAccountTree(2)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"0:12345678", "1:1", "2:11", "2:12", "1:2"}, collectAccountTree(root))
+		assert.Equal(t, "Account 11", root.Children[0].Children[0].Account.Name)
+	}
+}
+
+func TestAims_WalkAccountTreeCycle(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountTreeHandlers(t, map[string][]string{
+		testAccountId: {"1"},
+		"1":           {"2"},
+		"2":           {testAccountId, "1"},
+	})
+
+	root, err := client.WalkAccountTree(0)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"0:12345678", "1:1", "2:2"}, collectAccountTree(root))
+	}
+}
+
+func TestAims_WalkAccountTreeConcurrency(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	mux.HandleFunc(accountDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q}`, testAccountId)
+	})
+	mux.HandleFunc(fmt.Sprintf("/%s/", aimsServicePath), func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if r.URL.Path == fmt.Sprintf("/%s/%s/accounts/managed", aimsServicePath, testAccountId) {
+			fmt.Fprint(w, `{"accounts": [{"id": "1"}, {"id": "2"}, {"id": "3"}, {"id": "4"}, {"id": "5"}, {"id": "6"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"accounts": []}`)
+	})
+
+	root, err := client.WalkAccountTree(2)
+
+	if assert.NoError(t, err) {
+		assert.Len(t, root.Children, 6)
+		assert.LessOrEqual(t, maxInFlight, 2)
+	}
+}
+
+func TestAims_WalkAccountTreeError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	accountTreeHandlers(t, map[string][]string{
+		testAccountId: {"1"},
+	})
+
+	_, err := client.WalkAccountTree(1)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error listing managed accounts for account 1: error from makeRequest: HTTP status 404")
+}
+
+func TestAims_AccountNodeWalkStops(t *testing.T) {
+	root := &AccountNode{
+		Account: AccountDetails{ID: "root"},
+		Children: []*AccountNode{
+			{Account: AccountDetails{ID: "1"}},
+			{Account: AccountDetails{ID: "2"}},
+		},
+	}
+
+	visited := []string{}
+	err := root.Walk(func(node *AccountNode, depth int) error {
+		visited = append(visited, node.Account.ID)
+		if node.Account.ID == "1" {
+			return fmt.Errorf("stop")
+		}
+		return nil
+	})
+
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, []string{"root", "1"}, visited)
+}
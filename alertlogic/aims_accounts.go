@@ -16,6 +16,8 @@ type AccountDetails struct {
 	AccessibleLocations []string        `json:"accessible_locations,omitempty"`
 	DefaultLocation     string          `json:"default_location,omitempty"`
 	MfaRequired         bool            `json:"mfa_required,omitempty"`
+	IdleSessionTimeout  int64           `json:"idle_session_timeout,omitempty"`
+	MaxSessionLength    int64           `json:"max_session_length,omitempty"`
 	Created             ModifiedCreated `json:"created,omitempty"`
 	Modified            ModifiedCreated `json:"modified,omitempty"`
 }
@@ -29,8 +31,27 @@ const (
 	Managing AccountRelationship = "managing"
 )
 
+// AccountsList holds a list of accounts.
+type AccountsList struct {
+	Accounts []AccountDetails `json:"accounts"`
+}
+
+// CreateAccountRequest holds the managed account create request data.
+type CreateAccountRequest struct {
+	Name            string `json:"name"`
+	DefaultLocation string `json:"default_location,omitempty"`
+	MfaRequired     bool   `json:"mfa_required,omitempty"`
+}
+
+// UpdateAccountDetailsRequest holds the account update request data. Only fields that are set
+// are sent, so a request may update a single attribute without affecting the others.
+// `IdleSessionTimeout` and `MaxSessionLength` are in seconds.
 type UpdateAccountDetailsRequest struct {
-	MfaRequired bool `json:"mfa_required"`
+	Name               *string `json:"name,omitempty"`
+	DefaultLocation    *string `json:"default_location,omitempty"`
+	MfaRequired        *bool   `json:"mfa_required,omitempty"`
+	IdleSessionTimeout *int64  `json:"idle_session_timeout,omitempty"`
+	MaxSessionLength   *int64  `json:"max_session_length,omitempty"`
 }
 
 // GetAccountDetails gets details of an account.
@@ -59,20 +80,79 @@ func (api *API) GetAccountDetails() (AccountDetails, error) {
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-AccountRelationshipExists
 func (api *API) GetAccountRelationship(relatedAccountId string, accountRelationship AccountRelationship) (int, error) {
-	_, statusCode, err := api.makeRequest("GET", fmt.Sprintf("%s/%s/accounts/%s/%s", aimsServicePath, api.AccountID, accountRelationship, relatedAccountId), nil, nil, nil)
+	return api.accountRelationship("GET", relatedAccountId, accountRelationship)
+}
+
+// CreateManagedAccount creates a new account that is managed by the account.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-CreateManagedAccount
+func (api *API) CreateManagedAccount(account CreateAccountRequest) (AccountDetails, error) {
+	if account.Name == "" {
+		return AccountDetails{}, errors.New(errEmptyAccountName)
+	}
+
+	res, _, err := api.makeRequest("POST", fmt.Sprintf("%s/%s/accounts/%s", aimsServicePath, api.AccountID, Managed), nil, nil, account)
 
 	if err != nil {
-		return statusCode, errors.Wrap(err, errMakeRequestError)
+		return AccountDetails{}, errors.Wrap(err, errMakeRequestError)
 	}
 
-	return statusCode, nil
+	var r AccountDetails
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return AccountDetails{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// CreateAccountRelationship creates an `accountRelationship` relationship between the account and
+// `relatedAccountId`, for example making `relatedAccountId` a managed account with `Managed`.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-CreateAccountRelationship
+func (api *API) CreateAccountRelationship(relatedAccountId string, accountRelationship AccountRelationship) (int, error) {
+	return api.accountRelationship("PUT", relatedAccountId, accountRelationship)
+}
+
+// DeleteAccountRelationship removes an `accountRelationship` relationship between the account and
+// `relatedAccountId`.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-DeleteAccountRelationship
+func (api *API) DeleteAccountRelationship(relatedAccountId string, accountRelationship AccountRelationship) (int, error) {
+	return api.accountRelationship("DELETE", relatedAccountId, accountRelationship)
+}
+
+// ListManagedAccounts lists all accounts managed by the account.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-ListAccounts
+func (api *API) ListManagedAccounts() (AccountsList, error) {
+	return api.listAccounts(api.AccountID, Managed)
+}
+
+// ListManagingAccounts lists all accounts that manage the account.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-ListAccounts
+func (api *API) ListManagingAccounts() (AccountsList, error) {
+	return api.listAccounts(api.AccountID, Managing)
+}
+
+// ListBillsToAccounts lists all accounts that the account bills to.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-ListAccounts
+func (api *API) ListBillsToAccounts() (AccountsList, error) {
+	return api.listAccounts(api.AccountID, BillsTo)
 }
 
 // UpdateAccountDetails updates details of an account.
-// This endpoint only allows updating of the `mfa_enabled` value.
+// Only the fields set in `updateAccountDetailsRequest` are changed; use Bool, String and Int64 to
+// set them.
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Account_Resources-UpdateAccount
 func (api *API) UpdateAccountDetails(updateAccountDetailsRequest UpdateAccountDetailsRequest) (AccountDetails, error) {
+	if updateAccountDetailsRequest == (UpdateAccountDetailsRequest{}) {
+		return AccountDetails{}, errors.New(errEmptyUpdateAccountDetailsRequest)
+	}
+
 	res, _, err := api.makeRequest("POST", fmt.Sprintf("%s/%s/account", aimsServicePath, api.AccountID), nil, nil, updateAccountDetailsRequest)
 
 	if err != nil {
@@ -87,3 +167,31 @@ func (api *API) UpdateAccountDetails(updateAccountDetailsRequest UpdateAccountDe
 
 	return r, nil
 }
+
+// listAccounts holds shared logic for retrieving accounts related to `accountId` from the API.
+func (api *API) listAccounts(accountId string, accountRelationship AccountRelationship) (AccountsList, error) {
+	res, _, err := api.makeRequest("GET", fmt.Sprintf("%s/%s/accounts/%s", aimsServicePath, accountId, accountRelationship), nil, nil, nil)
+	if err != nil {
+		return AccountsList{}, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r AccountsList
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return AccountsList{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// accountRelationship has shared functionality for checking, creating or deleting account
+// relationships.
+func (api *API) accountRelationship(method string, relatedAccountId string, accountRelationship AccountRelationship) (int, error) {
+	_, statusCode, err := api.makeRequest(method, fmt.Sprintf("%s/%s/accounts/%s/%s", aimsServicePath, api.AccountID, accountRelationship, relatedAccountId), nil, nil, nil)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
@@ -2,6 +2,7 @@ package alertlogic
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"testing"
 
@@ -117,6 +118,9 @@ func TestAims_UpdateAccountDetails(t *testing.T) {
 	mux.HandleFunc(accountDetailsPath, func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
 
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"mfa_required": false}`, string(body))
+
 		w.Header().Set("content-type", "application/json")
 		fmt.Fprint(w, response)
 	})
@@ -133,9 +137,225 @@ func TestAims_UpdateAccountDetails(t *testing.T) {
 		Modified:            ModifiedCreated{At: 1430184599, By: "System"},
 	}
 
-	user, err := client.UpdateAccountDetails(UpdateAccountDetailsRequest{MfaRequired: false})
+	user, err := client.UpdateAccountDetails(UpdateAccountDetailsRequest{MfaRequired: Bool(false)})
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, user, want)
 	}
 }
+
+func TestAims_ListRelatedAccounts(t *testing.T) {
+	listFunctions := map[AccountRelationship]func() (AccountsList, error){
+		Managed:  func() (AccountsList, error) { return client.ListManagedAccounts() },
+		Managing: func() (AccountsList, error) { return client.ListManagingAccounts() },
+		BillsTo:  func() (AccountsList, error) { return client.ListBillsToAccounts() },
+	}
+
+	const response = `
+	{
+		"accounts": [{
+			"id": "98765432",
+			"name": "Child Company",
+			"active": true,
+			"version": 1,
+			"accessible_locations": ["insight-us-virginia"],
+			"default_location": "insight-us-virginia",
+			"mfa_required": true,
+			"created": {
+				"at": 1430184599,
+				"by": "System"
+			},
+			"modified": {
+				"at": 1430184599,
+				"by": "System"
+			}
+		}]
+	}`
+
+	want := AccountsList{
+		Accounts: []AccountDetails{
+			{
+				ID:                  testRelatedAccountId,
+				Name:                "Child Company",
+				Active:              true,
+				Version:             1,
+				AccessibleLocations: []string{"insight-us-virginia"},
+				DefaultLocation:     "insight-us-virginia",
+				MfaRequired:         true,
+				Created:             ModifiedCreated{At: 1430184599, By: "System"},
+				Modified:            ModifiedCreated{At: 1430184599, By: "System"},
+			},
+		},
+	}
+
+	for relationship, listFunction := range listFunctions {
+		setup()
+
+		mux.HandleFunc(fmt.Sprintf("/%s/%s/accounts/%s", aimsServicePath, testAccountId, relationship), func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+
+			w.Header().Set("content-type", "application/json")
+			fmt.Fprint(w, response)
+		})
+
+		accounts, err := listFunction()
+
+		if assert.NoError(t, err, "relationship %s", relationship) {
+			assert.Equal(t, accounts, want)
+		}
+
+		teardown()
+	}
+}
+
+func TestAims_CreateManagedAccount(t *testing.T) {
+	setup()
+	defer teardown()
+
+	const response = `
+	{
+		"id": "98765432",
+		"name": "Child Company",
+		"active": true,
+		"version": 1,
+		"accessible_locations": ["insight-us-virginia"],
+		"default_location": "insight-us-virginia",
+		"mfa_required": true,
+		"created": {
+			"at": 1430184599,
+			"by": "System"
+		},
+		"modified": {
+			"at": 1430184599,
+			"by": "System"
+		}
+	}`
+
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/accounts/%s", aimsServicePath, testAccountId, Managed), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name": "Child Company", "default_location": "insight-us-virginia", "mfa_required": true}`, string(body))
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, response)
+	})
+
+	want := AccountDetails{
+		ID:                  testRelatedAccountId,
+		Name:                "Child Company",
+		Active:              true,
+		Version:             1,
+		AccessibleLocations: []string{"insight-us-virginia"},
+		DefaultLocation:     "insight-us-virginia",
+		MfaRequired:         true,
+		Created:             ModifiedCreated{At: 1430184599, By: "System"},
+		Modified:            ModifiedCreated{At: 1430184599, By: "System"},
+	}
+
+	account, err := client.CreateManagedAccount(CreateAccountRequest{
+		Name:            "Child Company",
+		DefaultLocation: "insight-us-virginia",
+		MfaRequired:     true,
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, account, want)
+	}
+}
+
+func TestAims_CreateManagedAccountMissingName(t *testing.T) {
+	_, err := client.CreateManagedAccount(CreateAccountRequest{})
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyAccountName)
+}
+
+func TestAims_CreateAccountRelationship(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(accountRelationshipPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.CreateAccountRelationship(testRelatedAccountId, Managed)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_CreateAccountRelationshipError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	errorResponse := `{"error":"relationship_exists"}`
+
+	mux.HandleFunc(accountRelationshipPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, errorResponse)
+	})
+
+	respCode, err := client.CreateAccountRelationship(testRelatedAccountId, Managed)
+
+	assert.Error(t, err)
+	assert.Equal(t, respCode, http.StatusBadRequest)
+	assert.Equal(t, err.Error(), fmt.Sprintf("error from makeRequest: %s", errorResponse))
+}
+
+func TestAims_DeleteAccountRelationship(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(accountRelationshipPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.DeleteAccountRelationship(testRelatedAccountId, Managed)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_UpdateAccountDetailsPartial(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(accountDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name": "New Name", "idle_session_timeout": 900}`, string(body))
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"id": "12345678", "name": "New Name", "mfa_required": true, "idle_session_timeout": 900}`)
+	})
+
+	want := AccountDetails{
+		ID:                 testAccountId,
+		Name:               "New Name",
+		MfaRequired:        true,
+		IdleSessionTimeout: 900,
+	}
+
+	account, err := client.UpdateAccountDetails(UpdateAccountDetailsRequest{
+		Name:               String("New Name"),
+		IdleSessionTimeout: Int64(900),
+	})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, account, want)
+	}
+}
+
+func TestAims_UpdateAccountDetailsEmptyRequest(t *testing.T) {
+	_, err := client.UpdateAccountDetails(UpdateAccountDetailsRequest{})
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyUpdateAccountDetailsRequest)
+}
@@ -0,0 +1,254 @@
+package alertlogic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ActivityKind is the kind of credential an ActivityRecord describes.
+type ActivityKind string
+
+const (
+	UserActivity      ActivityKind = "user"
+	AccessKeyActivity ActivityKind = "access_key"
+)
+
+// ActivityFlag marks a user or access key that may need attention.
+type ActivityFlag string
+
+const (
+	// FlagInactive marks a user or access key that has not been used for longer than
+	// `UserActivityOptions.InactiveDays`.
+	FlagInactive ActivityFlag = "inactive"
+	// FlagNeverLoggedIn marks a user who has never logged in, or an access key that has never
+	// been used to authenticate.
+	FlagNeverLoggedIn ActivityFlag = "never_logged_in"
+	// FlagNotificationsOnly marks a user who only receives notifications and cannot log in.
+	FlagNotificationsOnly ActivityFlag = "notifications_only"
+)
+
+// activityReportColumns are the columns written by UserActivityReport.WriteCSV.
+var activityReportColumns = []string{"account_id", "kind", "user_id", "name", "email", "access_key_id", "label", "active", "created", "last_activity", "age_days", "flags"}
+
+// UserActivityOptions control GetUserActivityReport.
+// Users and access keys whose last activity is more than `InactiveDays` ago are flagged as
+// inactive; zero disables the flag. `IncludeManagedAccounts` also reports on every account
+// in the hierarchy of managed accounts below the account, as found by WalkAccountTree.
+type UserActivityOptions struct {
+	InactiveDays           int
+	IncludeManagedAccounts bool
+}
+
+// ActivityRecord is the activity of a single user or access key.
+// `LastActivity` is the last login of a user or the last use of an access key, and is zero if
+// there was none. `AgeDays` is the number of whole days since `LastActivity`, or since the user
+// or access key was created if it has never been used.
+type ActivityRecord struct {
+	AccountID    string         `json:"account_id"`
+	Kind         ActivityKind   `json:"kind"`
+	UserID       string         `json:"user_id"`
+	Name         string         `json:"name"`
+	Email        string         `json:"email"`
+	AccessKeyID  string         `json:"access_key_id,omitempty"`
+	Label        string         `json:"label,omitempty"`
+	Active       bool           `json:"active"`
+	Created      time.Time      `json:"created"`
+	LastActivity time.Time      `json:"last_activity"`
+	AgeDays      int            `json:"age_days"`
+	Flags        []ActivityFlag `json:"flags"`
+}
+
+// Flagged reports whether the record has `flag`.
+func (r ActivityRecord) Flagged(flag ActivityFlag) bool {
+	for _, f := range r.Flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserActivityReport is the activity of every user and access key in one or more accounts.
+type UserActivityReport struct {
+	GeneratedAt  time.Time        `json:"generated_at"`
+	InactiveDays int              `json:"inactive_days"`
+	Records      []ActivityRecord `json:"records"`
+}
+
+// Flagged returns the records that have `flag`.
+func (r UserActivityReport) Flagged(flag ActivityFlag) []ActivityRecord {
+	records := []ActivityRecord{}
+	for _, record := range r.Records {
+		if record.Flagged(flag) {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// WriteCSV writes the report as CSV with a header row. Times are RFC 3339 and are empty when
+// zero, and flags are separated by `;`.
+func (r UserActivityReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(activityReportColumns); err != nil {
+		return errors.Wrap(err, "error writing activity report")
+	}
+
+	for _, record := range r.Records {
+		flags := make([]string, len(record.Flags))
+		for i, flag := range record.Flags {
+			flags[i] = string(flag)
+		}
+
+		err := writer.Write([]string{
+			record.AccountID,
+			string(record.Kind),
+			record.UserID,
+			record.Name,
+			record.Email,
+			record.AccessKeyID,
+			record.Label,
+			strconv.FormatBool(record.Active),
+			formatActivityTime(record.Created),
+			formatActivityTime(record.LastActivity),
+			strconv.Itoa(record.AgeDays),
+			strings.Join(flags, ";"),
+		})
+		if err != nil {
+			return errors.Wrap(err, "error writing activity report")
+		}
+	}
+
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "error writing activity report")
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r UserActivityReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return errors.Wrap(encoder.Encode(r), "error writing activity report")
+}
+
+// LastLogin returns the time the user last logged in. The zero time is returned when the user
+// has never logged in or the user was retrieved without their credential.
+func (u User) LastLogin() time.Time {
+	if u.UserCredential == nil || u.UserCredential.LastLogin == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(u.UserCredential.LastLogin), 0)
+}
+
+// GetUserActivityReport lists every user and access key in the account, and optionally in every
+// account it manages directly or indirectly, with their last activity, flagging inactive,
+// never-used and notifications-only users and access keys.
+func (api *API) GetUserActivityReport(options UserActivityOptions) (UserActivityReport, error) {
+	now := timeNow()
+	report := UserActivityReport{GeneratedAt: now, InactiveDays: options.InactiveDays, Records: []ActivityRecord{}}
+
+	accountIds := []string{api.AccountID}
+	if options.IncludeManagedAccounts {
+		root, err := api.WalkAccountTree(0)
+		if err != nil {
+			return UserActivityReport{}, err
+		}
+
+		accountIds = []string{}
+		_ = root.Walk(func(node *AccountNode, depth int) error {
+			accountIds = append(accountIds, node.Account.ID)
+			return nil
+		})
+	}
+
+	for _, accountId := range accountIds {
+		users, err := api.getUsers(fmt.Sprintf("%s/%s/users", aimsServicePath, accountId), true, true, false, "")
+		if err != nil {
+			return UserActivityReport{}, errors.Wrapf(err, "error listing users in account %s", accountId)
+		}
+
+		for _, user := range users.Users {
+			record := ActivityRecord{
+				AccountID:    accountId,
+				Kind:         UserActivity,
+				UserID:       user.ID,
+				Name:         user.Name,
+				Email:        user.Email,
+				Active:       user.Active,
+				Created:      user.Created.Time(),
+				LastActivity: user.LastLogin(),
+			}
+			if user.NotificationsOnly {
+				record.Flags = append(record.Flags, FlagNotificationsOnly)
+			}
+			report.Records = append(report.Records, record.withAge(now, options.InactiveDays, !user.NotificationsOnly))
+
+			if user.AccessKeys == nil {
+				continue
+			}
+			for _, accessKey := range *user.AccessKeys {
+				record := ActivityRecord{
+					AccountID:    accountId,
+					Kind:         AccessKeyActivity,
+					UserID:       user.ID,
+					Name:         user.Name,
+					Email:        user.Email,
+					AccessKeyID:  accessKey.AccessKeyID,
+					Label:        accessKey.Label,
+					Active:       user.Active,
+					Created:      accessKey.Created.Time(),
+					LastActivity: accessKey.LastUsed(),
+				}
+				report.Records = append(report.Records, record.withAge(now, options.InactiveDays, true))
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// withAge sets the record's age and its inactivity flags. Records without `canLogIn` are never
+// flagged as inactive or never logged in.
+func (r ActivityRecord) withAge(now time.Time, inactiveDays int, canLogIn bool) ActivityRecord {
+	since := r.LastActivity
+	if since.IsZero() {
+		since = r.Created
+	}
+	if !since.IsZero() {
+		r.AgeDays = int(now.Sub(since) / (24 * time.Hour))
+	}
+
+	if canLogIn {
+		if r.LastActivity.IsZero() {
+			r.Flags = append(r.Flags, FlagNeverLoggedIn)
+		}
+		if inactiveDays > 0 && !since.IsZero() && r.AgeDays > inactiveDays {
+			r.Flags = append(r.Flags, FlagInactive)
+		}
+	}
+
+	if r.Flags == nil {
+		r.Flags = []ActivityFlag{}
+	}
+
+	return r
+}
+
+// formatActivityTime formats a time for CSV output, returning an empty string for the zero time.
+func formatActivityTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
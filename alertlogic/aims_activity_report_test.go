@@ -0,0 +1,185 @@
+package alertlogic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testActivityNow is the time activity reports are generated at in tests, 2021-10-01T00:00:00Z.
+const testActivityNow = 1633046400
+
+// activityReportHandlers serves users with credentials and access keys for the account and one
+// managed account.
+func activityReportHandlers(t *testing.T) {
+	day := 24 * 60 * 60
+
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("include_access_keys"))
+		assert.Equal(t, "true", r.URL.Query().Get("include_user_credential"))
+
+		fmt.Fprintf(w, `{"users": [
+			{
+				"id": %q, "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "active": true,
+				"created": {"at": %d}, "user_credential": {"last_login": %d},
+				"access_keys": [
+					{"access_key_id": "FRESH", "label": "ci", "created": {"at": %d}, "last_login": %d},
+					{"access_key_id": "UNUSED", "label": "old", "created": {"at": %d}}
+				]
+			},
+			{"id": "NOTIFY", "name": "Pager", "email": "pager@bobloblawlaw.com", "active": true, "notifications_only": true, "created": {"at": %d}}
+		]}`, testUserId, testActivityNow-400*day, testActivityNow-100*day, testActivityNow-50*day, testActivityNow-day, testActivityNow-200*day, testActivityNow-300*day)
+	})
+
+	mux.HandleFunc(accountDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q}`, testAccountId)
+	})
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/accounts/managed", aimsServicePath, testAccountId), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"accounts": [{"id": %q}]}`, testRelatedAccountId)
+	})
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/accounts/managed", aimsServicePath, testRelatedAccountId), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"accounts": [{"id": "NESTED"}]}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/%s/NESTED/accounts/managed", aimsServicePath), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"accounts": []}`)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/users", aimsServicePath, testRelatedAccountId), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"users": [{"id": "NEW", "name": "Lucille Bluth", "email": "lucille@bluth.com", "created": {"at": %d}}]}`, testActivityNow-2*day)
+	})
+	mux.HandleFunc(fmt.Sprintf("/%s/NESTED/users", aimsServicePath), func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"users": [{"id": "GOB", "name": "Gob Bluth", "email": "gob@bluth.com", "created": {"at": %d}}]}`, testActivityNow-3*day)
+	})
+}
+
+func TestAims_GetUserActivityReport(t *testing.T) {
+	setup()
+	defer teardown()
+
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(testActivityNow, 0) }
+
+	activityReportHandlers(t)
+
+	report, err := client.GetUserActivityReport(UserActivityOptions{InactiveDays: 90})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Unix(testActivityNow, 0), report.GeneratedAt)
+		if assert.Len(t, report.Records, 4) {
+			assert.Equal(t, ActivityRecord{
+				AccountID:    testAccountId,
+				Kind:         UserActivity,
+				UserID:       testUserId,
+				Name:         testUserFullName,
+				Email:        testEmail,
+				Active:       true,
+				Created:      time.Unix(testActivityNow-400*24*60*60, 0),
+				LastActivity: time.Unix(testActivityNow-100*24*60*60, 0),
+				AgeDays:      100,
+				Flags:        []ActivityFlag{FlagInactive},
+			}, report.Records[0])
+
+			assert.Equal(t, AccessKeyActivity, report.Records[1].Kind)
+			assert.Equal(t, "FRESH", report.Records[1].AccessKeyID)
+			assert.Equal(t, 1, report.Records[1].AgeDays)
+			assert.Empty(t, report.Records[1].Flags)
+
+			assert.Equal(t, "UNUSED", report.Records[2].AccessKeyID)
+			assert.Equal(t, 200, report.Records[2].AgeDays)
+			assert.Equal(t, []ActivityFlag{FlagNeverLoggedIn, FlagInactive}, report.Records[2].Flags)
+
+			assert.Equal(t, []ActivityFlag{FlagNotificationsOnly}, report.Records[3].Flags)
+		}
+
+		assert.Len(t, report.Flagged(FlagInactive), 2)
+		assert.Len(t, report.Flagged(FlagNeverLoggedIn), 1)
+	}
+}
+
+func TestAims_GetUserActivityReportManagedAccounts(t *testing.T) {
+	setup()
+	defer teardown()
+
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(testActivityNow, 0) }
+
+	activityReportHandlers(t)
+
+	report, err := client.GetUserActivityReport(UserActivityOptions{IncludeManagedAccounts: true})
+
+	if assert.NoError(t, err) && assert.Len(t, report.Records, 6) {
+		assert.Empty(t, report.Flagged(FlagInactive))
+		assert.Equal(t, ActivityRecord{
+			AccountID: testRelatedAccountId,
+			Kind:      UserActivity,
+			UserID:    "NEW",
+			Name:      "Lucille Bluth",
+			Email:     "lucille@bluth.com",
+			Created:   time.Unix(testActivityNow-2*24*60*60, 0),
+			AgeDays:   2,
+			Flags:     []ActivityFlag{FlagNeverLoggedIn},
+		}, report.Records[4])
+		assert.Equal(t, "NESTED", report.Records[5].AccountID)
+		assert.Equal(t, "GOB", report.Records[5].UserID)
+	}
+}
+
+func TestAims_GetUserActivityReportError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.GetUserActivityReport(UserActivityOptions{})
+	assert.Contains(t, err.Error(), "error listing users in account 12345678")
+
+	_, err = client.GetUserActivityReport(UserActivityOptions{IncludeManagedAccounts: true})
+	assert.Contains(t, err.Error(), "HTTP status 404")
+}
+
+func TestAims_UserActivityReportWriteCSV(t *testing.T) {
+	report := UserActivityReport{Records: []ActivityRecord{
+		{
+			AccountID:   testAccountId,
+			Kind:        AccessKeyActivity,
+			UserID:      testUserId,
+			Name:        testUserFullName,
+			Email:       testEmail,
+			AccessKeyID: testAccessKeyId,
+			Label:       "ci, nightly",
+			Active:      true,
+			Created:     time.Unix(testActivityNow, 0),
+			AgeDays:     3,
+			Flags:       []ActivityFlag{FlagNeverLoggedIn, FlagInactive},
+		},
+	}}
+
+	var buf bytes.Buffer
+	err := report.WriteCSV(&buf)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.Join([]string{
+			"account_id,kind,user_id,name,email,access_key_id,label,active,created,last_activity,age_days,flags",
+			fmt.Sprintf(`12345678,access_key,%s,Bob Loblaw,bob@bobloblawlaw.com,%s,"ci, nightly",true,2021-10-01T00:00:00Z,,3,never_logged_in;inactive`, testUserId, testAccessKeyId),
+			"",
+		}, "\n"), buf.String())
+	}
+}
+
+func TestAims_UserActivityReportWriteJSON(t *testing.T) {
+	report := UserActivityReport{InactiveDays: 30, Records: []ActivityRecord{{Kind: UserActivity, UserID: testUserId, Flags: []ActivityFlag{FlagNotificationsOnly}}}}
+
+	var buf bytes.Buffer
+	err := report.WriteJSON(&buf)
+
+	if assert.NoError(t, err) {
+		var decoded UserActivityReport
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, 30, decoded.InactiveDays)
+		assert.Equal(t, []ActivityFlag{FlagNotificationsOnly}, decoded.Records[0].Flags)
+	}
+}
@@ -0,0 +1,365 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AIMSBackupVersion is the version of the document written by Export. Restore rejects documents
+// with any other version.
+const AIMSBackupVersion = 1
+
+// AIMSBackup is a snapshot of an account's users, custom roles and role assignments. It holds
+// no passwords, access keys or other secrets.
+type AIMSBackup struct {
+	Version    int          `json:"version"`
+	AccountID  string       `json:"account_id"`
+	ExportedAt time.Time    `json:"exported_at"`
+	Roles      []BackupRole `json:"roles"`
+	Users      []BackupUser `json:"users"`
+}
+
+// BackupRole is a custom role in an AIMSBackup.
+type BackupRole struct {
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Permissions map[string]Permission `json:"permissions"`
+}
+
+// BackupUser is a user in an AIMSBackup. `Roles` are the names of the user's roles, including
+// global roles, so that they can be resolved in another account.
+type BackupUser struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Email             string   `json:"email"`
+	Active            bool     `json:"active"`
+	MobilePhone       string   `json:"mobile_phone,omitempty"`
+	NotificationsOnly bool     `json:"notifications_only,omitempty"`
+	Roles             []string `json:"roles"`
+}
+
+// ConflictPolicy decides what Restore does with a role or user that already exists in the
+// account. Roles conflict by name and users by email address.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves existing roles and users unchanged.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite updates existing roles and users to match the backup, including revoking
+	// roles that a user does not hold in the backup.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictFail makes Restore fail without changing anything if any role or user exists.
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// RestoreOptions control Restore. The zero value skips conflicts.
+type RestoreOptions struct {
+	OnConflict ConflictPolicy
+}
+
+// RestoreAction is what Restore did with a single role or user.
+type RestoreAction string
+
+const (
+	RestoreCreated RestoreAction = "created"
+	RestoreUpdated RestoreAction = "updated"
+	RestoreSkipped RestoreAction = "skipped"
+	RestoreFailed  RestoreAction = "failed"
+)
+
+// RestoreResult is the outcome of restoring a single role or user. `ID` is the ID in the
+// restored account and `Name` is the role name or user email address.
+type RestoreResult struct {
+	Name   string        `json:"name"`
+	ID     string        `json:"id,omitempty"`
+	Action RestoreAction `json:"action"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// RestoreReport holds the result of every role and user in a restored AIMSBackup, in backup
+// order.
+type RestoreReport struct {
+	Roles []RestoreResult `json:"roles"`
+	Users []RestoreResult `json:"users"`
+}
+
+// Export writes the account's users, custom roles and role assignments to `w` as an AIMSBackup
+// JSON document.
+func (api *API) Export(w io.Writer) error {
+	backup, err := api.exportBackup()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return errors.Wrap(encoder.Encode(backup), "error writing backup")
+}
+
+// Restore reads an AIMSBackup JSON document from `r` and applies it to the account, which does
+// not need to be the account it was exported from. Custom roles are created first, then users
+// are created and granted their roles. Created users are not given a password and are emailed a
+// link to set one.
+// Existing roles and users are handled according to `options.OnConflict`. Apart from
+// ConflictFail, a failed role or user does not stop the rest of the restore. The returned error
+// is non-nil if anything failed, and the report holds the result of every role and user.
+func (api *API) Restore(r io.Reader, options RestoreOptions) (RestoreReport, error) {
+	var backup AIMSBackup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return RestoreReport{}, errors.Wrap(err, "error reading backup")
+	}
+	if backup.Version != AIMSBackupVersion {
+		return RestoreReport{}, errors.Errorf("unsupported backup version %d", backup.Version)
+	}
+
+	switch options.OnConflict {
+	case "":
+		options.OnConflict = ConflictSkip
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return RestoreReport{}, errors.Errorf("unknown conflict policy %q", options.OnConflict)
+	}
+
+	roles, err := api.ListRoles()
+	if err != nil {
+		return RestoreReport{}, err
+	}
+	users, err := api.ListUsers(false, false, true, "")
+	if err != nil {
+		return RestoreReport{}, err
+	}
+
+	liveRoles := map[string]Role{}
+	for _, role := range roles.Roles {
+		liveRoles[role.Name] = role
+	}
+	liveUsers := map[string]User{}
+	for _, user := range users.Users {
+		liveUsers[strings.ToLower(user.Email)] = user
+	}
+
+	if options.OnConflict == ConflictFail {
+		if err := backupConflicts(backup, liveRoles, liveUsers); err != nil {
+			return RestoreReport{}, err
+		}
+	}
+
+	report := RestoreReport{Roles: []RestoreResult{}, Users: []RestoreResult{}}
+	roleIds := map[string]string{}
+	for _, role := range roles.Roles {
+		roleIds[role.Name] = role.ID
+	}
+
+	for _, role := range backup.Roles {
+		result := api.restoreRole(role, liveRoles, options.OnConflict)
+		if result.ID != "" {
+			roleIds[role.Name] = result.ID
+		}
+		report.Roles = append(report.Roles, result)
+	}
+
+	for _, user := range backup.Users {
+		report.Users = append(report.Users, api.restoreUser(user, liveUsers, roleIds, options.OnConflict))
+	}
+
+	failed, total := 0, len(report.Roles)+len(report.Users)
+	for _, result := range append(append([]RestoreResult{}, report.Roles...), report.Users...) {
+		if result.Action == RestoreFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return report, errors.Errorf("%d of %d roles and users failed to restore", failed, total)
+	}
+
+	return report, nil
+}
+
+// exportBackup builds an AIMSBackup of the account.
+func (api *API) exportBackup() (AIMSBackup, error) {
+	roles, err := api.ListRoles()
+	if err != nil {
+		return AIMSBackup{}, err
+	}
+	users, err := api.ListUsers(false, false, true, "")
+	if err != nil {
+		return AIMSBackup{}, err
+	}
+
+	backup := AIMSBackup{
+		Version:    AIMSBackupVersion,
+		AccountID:  api.AccountID,
+		ExportedAt: timeNow().UTC(),
+		Roles:      []BackupRole{},
+		Users:      []BackupUser{},
+	}
+
+	roleNames := map[string]string{}
+	for _, role := range roles.Roles {
+		roleNames[role.ID] = role.Name
+		if !role.Global {
+			backup.Roles = append(backup.Roles, BackupRole{ID: role.ID, Name: role.Name, Permissions: role.Permissions})
+		}
+	}
+
+	for _, user := range users.Users {
+		backupUser := BackupUser{
+			ID:                user.ID,
+			Name:              user.Name,
+			Email:             user.Email,
+			Active:            user.Active,
+			NotificationsOnly: user.NotificationsOnly,
+			Roles:             []string{},
+		}
+		if user.MobilePhone != nil {
+			backupUser.MobilePhone = *user.MobilePhone
+		}
+		if user.RoleIds != nil {
+			for _, roleId := range *user.RoleIds {
+				backupUser.Roles = append(backupUser.Roles, roleName(roleNames, roleId))
+			}
+		}
+		backup.Users = append(backup.Users, backupUser)
+	}
+
+	return backup, nil
+}
+
+// restoreRole creates a single role, or handles an existing role with the same name according
+// to `onConflict`.
+func (api *API) restoreRole(role BackupRole, liveRoles map[string]Role, onConflict ConflictPolicy) RestoreResult {
+	result := RestoreResult{Name: role.Name}
+
+	live, exists := liveRoles[role.Name]
+	switch {
+	case !exists:
+		created, err := api.CreateRole(CreateRoleRequest{Name: role.Name, Permissions: role.Permissions})
+		if err != nil {
+			return result.fail(errors.Wrap(err, "error creating role"))
+		}
+		result.ID, result.Action = created.ID, RestoreCreated
+	case onConflict != ConflictOverwrite || samePermissions(live.Permissions, role.Permissions):
+		result.ID, result.Action = live.ID, RestoreSkipped
+	case live.Global:
+		result.ID = live.ID
+		return result.fail(errors.Errorf("role %q is a global role and cannot be changed", role.Name))
+	default:
+		result.ID = live.ID
+		if _, err := api.UpdateRole(live.ID, UpdateRoleRequest{Permissions: role.Permissions}); err != nil {
+			return result.fail(errors.Wrap(err, "error updating role"))
+		}
+		result.Action = RestoreUpdated
+	}
+
+	return result
+}
+
+// restoreUser creates a single user and grants their roles, or handles an existing user with the
+// same email address according to `onConflict`.
+func (api *API) restoreUser(user BackupUser, liveUsers map[string]User, roleIds map[string]string, onConflict ConflictPolicy) RestoreResult {
+	result := RestoreResult{Name: user.Email}
+
+	desired := map[string]bool{}
+	for _, name := range user.Roles {
+		roleId, ok := roleIds[name]
+		if !ok {
+			return result.fail(errors.Errorf("role %q does not exist", name))
+		}
+		desired[roleId] = true
+	}
+
+	held := map[string]bool{}
+	live, exists := liveUsers[strings.ToLower(user.Email)]
+	switch {
+	case !exists:
+		created, err := api.CreateUser(CreateUserRequest{
+			Name:              user.Name,
+			Email:             user.Email,
+			Active:            user.Active,
+			MobilePhone:       user.MobilePhone,
+			NotificationsOnly: user.NotificationsOnly,
+		}, false)
+		if err != nil {
+			return result.fail(errors.Wrap(err, "error creating user"))
+		}
+		result.ID, result.Action = created.ID, RestoreCreated
+		// Inactive is the zero value of CreateUserRequest.Active, so it is not sent on creation.
+		if !user.Active {
+			if _, err := api.DeactivateUser(created.ID); err != nil {
+				return result.fail(errors.Wrap(err, "error deactivating user"))
+			}
+		}
+	case onConflict != ConflictOverwrite:
+		result.ID, result.Action = live.ID, RestoreSkipped
+		return result
+	default:
+		result.ID = live.ID
+		if _, err := api.UpdateUserDetails(live.ID, UpdateUserRequest{
+			Name:              String(user.Name),
+			Active:            Bool(user.Active),
+			MobilePhone:       String(user.MobilePhone),
+			NotificationsOnly: Bool(user.NotificationsOnly),
+		}, false); err != nil {
+			return result.fail(errors.Wrap(err, "error updating user"))
+		}
+		if live.RoleIds != nil {
+			for _, roleId := range *live.RoleIds {
+				held[roleId] = true
+				if desired[roleId] {
+					continue
+				}
+				if _, err := api.RevokeUserRole(live.ID, roleId); err != nil {
+					return result.fail(errors.Wrapf(err, "error revoking role %s", roleId))
+				}
+			}
+		}
+		result.Action = RestoreUpdated
+	}
+
+	for _, name := range user.Roles {
+		roleId := roleIds[name]
+		if held[roleId] {
+			continue
+		}
+		if _, err := api.GrantUserRole(result.ID, roleId); err != nil {
+			return result.fail(errors.Wrapf(err, "error granting role %q", name))
+		}
+		held[roleId] = true
+	}
+
+	return result
+}
+
+// fail marks the result as failed with `err`.
+func (r RestoreResult) fail(err error) RestoreResult {
+	r.Action = RestoreFailed
+	r.Error = err.Error()
+
+	return r
+}
+
+// backupConflicts returns an error naming every role and user in `backup` that already exists.
+func backupConflicts(backup AIMSBackup, liveRoles map[string]Role, liveUsers map[string]User) error {
+	var conflicts []string
+	for _, role := range backup.Roles {
+		if _, exists := liveRoles[role.Name]; exists {
+			conflicts = append(conflicts, "role "+role.Name)
+		}
+	}
+	for _, user := range backup.Users {
+		if _, exists := liveUsers[strings.ToLower(user.Email)]; exists {
+			conflicts = append(conflicts, "user "+user.Email)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return errors.Errorf("backup conflicts with existing %s", strings.Join(conflicts, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package alertlogic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testBackup = `{
+	"version": 1,
+	"account_id": "98765432",
+	"roles": [
+		{"id": "OLD-AUDIT", "name": "Auditors", "permissions": {"*:own:get:*": "allowed"}},
+		{"id": "OLD-POWER", "name": "Power User", "permissions": {"*:own:*:*": "allowed"}}
+	],
+	"users": [
+		{"id": "OLD-BOB", "name": "Bob Loblaw", "email": "BOB@bobloblawlaw.com", "active": false, "roles": ["Auditors"]},
+		{"id": "OLD-LUCILLE", "name": "Lucille Bluth", "email": "lucille@bluth.com", "active": true, "mobile_phone": "123-555-0123", "roles": ["Auditors", "Administrator"]},
+		{"id": "OLD-GOB", "name": "Gob Bluth", "email": "gob@bluth.com", "active": true, "roles": ["Magicians"]}
+	]
+}`
+
+// backupHandlers serves the live roles and users of the restored account and records every
+// change request as "METHOD path body".
+func backupHandlers(t *testing.T) *[]string {
+	requests := []string{}
+	record := func(r *http.Request) {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+		requests = append(requests, strings.TrimSpace(fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body.String())))
+	}
+
+	mux.HandleFunc(listRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			record(r)
+			fmt.Fprint(w, `{"id": "NEW-AUDIT", "name": "Auditors"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"roles": [
+			{"id": %q, "name": "Power User", "permissions": {"*:own:get:*": "allowed"}},
+			{"id": %q, "name": "Administrator", "permissions": {"*:*:*:*": "allowed"}, "global": true}
+		]}`, testPowerRoleId, testAdminRoleId)
+	})
+
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			record(r)
+			fmt.Fprint(w, `{"id": "NEW-LUCILLE"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"users": [
+			{"id": %q, "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "active": true, "mobile_phone": "555-0100", "role_ids": [%q]}
+		]}`, testUserId, testPowerRoleId)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/", aimsServicePath, testAccountId), func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		if r.Method == "POST" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return &requests
+}
+
+func TestAims_Export(t *testing.T) {
+	setup()
+	defer teardown()
+
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(1633046400, 0) }
+
+	backupHandlers(t)
+
+	var buf bytes.Buffer
+	err := client.Export(&buf)
+
+	if assert.NoError(t, err) {
+		var backup AIMSBackup
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &backup))
+		assert.Equal(t, AIMSBackup{
+			Version:    AIMSBackupVersion,
+			AccountID:  testAccountId,
+			ExportedAt: time.Unix(1633046400, 0).UTC(),
+			Roles:      []BackupRole{{ID: testPowerRoleId, Name: "Power User", Permissions: map[string]Permission{"*:own:get:*": Allowed}}},
+			Users:      []BackupUser{{ID: testUserId, Name: testUserFullName, Email: testEmail, Active: true, MobilePhone: "555-0100", Roles: []string{"Power User"}}},
+		}, backup)
+	}
+}
+
+func TestAims_ExportError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var buf bytes.Buffer
+	err := client.Export(&buf)
+
+	assert.Error(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestAims_Restore(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := backupHandlers(t)
+
+	report, err := client.Restore(strings.NewReader(testBackup), RestoreOptions{})
+
+	assert.EqualError(t, err, "1 of 5 roles and users failed to restore")
+	assert.Equal(t, []RestoreResult{
+		{Name: "Auditors", ID: "NEW-AUDIT", Action: RestoreCreated},
+		{Name: "Power User", ID: testPowerRoleId, Action: RestoreSkipped},
+	}, report.Roles)
+	assert.Equal(t, []RestoreResult{
+		{Name: "BOB@bobloblawlaw.com", ID: testUserId, Action: RestoreSkipped},
+		{Name: "lucille@bluth.com", ID: "NEW-LUCILLE", Action: RestoreCreated},
+		{Name: "gob@bluth.com", Action: RestoreFailed, Error: `role "Magicians" does not exist`},
+	}, report.Users)
+	assert.Equal(t, []string{
+		fmt.Sprintf(`POST %s {"name":"Auditors","permissions":{"*:own:get:*":"allowed"}}`, listRolesPath),
+		fmt.Sprintf(`POST %s {"name":"Lucille Bluth","email":"lucille@bluth.com","active":true,"mobile_phone":"123-555-0123"}`, listUsersPath),
+		fmt.Sprintf("PUT /%s/%s/users/NEW-LUCILLE/roles/NEW-AUDIT", aimsServicePath, testAccountId),
+		fmt.Sprintf("PUT /%s/%s/users/NEW-LUCILLE/roles/%s", aimsServicePath, testAccountId, testAdminRoleId),
+	}, *requests)
+}
+
+func TestAims_RestoreInactiveUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := backupHandlers(t)
+
+	report, err := client.Restore(strings.NewReader(`{
+		"version": 1,
+		"users": [{"id": "OLD-BUSTER", "name": "Buster Bluth", "email": "buster@bluth.com", "active": false}]
+	}`), RestoreOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []RestoreResult{{Name: "buster@bluth.com", ID: "NEW-LUCILLE", Action: RestoreCreated}}, report.Users)
+	assert.Equal(t, []string{
+		fmt.Sprintf(`POST %s {"name":"Buster Bluth","email":"buster@bluth.com"}`, listUsersPath),
+		fmt.Sprintf(`POST /%s/%s/users/NEW-LUCILLE {"active":false}`, aimsServicePath, testAccountId),
+	}, *requests)
+}
+
+func TestAims_RestoreOverwrite(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := backupHandlers(t)
+
+	report, err := client.Restore(strings.NewReader(testBackup), RestoreOptions{OnConflict: ConflictOverwrite})
+
+	assert.Error(t, err)
+	assert.Equal(t, RestoreUpdated, report.Roles[1].Action)
+	assert.Equal(t, RestoreUpdated, report.Users[0].Action)
+	assert.Equal(t, []string{
+		fmt.Sprintf(`POST %s {"name":"Auditors","permissions":{"*:own:get:*":"allowed"}}`, listRolesPath),
+		fmt.Sprintf(`POST /%s/%s/roles/%s {"permissions":{"*:own:*:*":"allowed"}}`, aimsServicePath, testAccountId, testPowerRoleId),
+		fmt.Sprintf(`POST %s {"name":"Bob Loblaw","active":false,"mobile_phone":"","notifications_only":false}`, updateUserPath),
+		fmt.Sprintf("DELETE /%s/%s/users/%s/roles/%s", aimsServicePath, testAccountId, testUserId, testPowerRoleId),
+		fmt.Sprintf("PUT /%s/%s/users/%s/roles/NEW-AUDIT", aimsServicePath, testAccountId, testUserId),
+	}, (*requests)[:5])
+}
+
+func TestAims_RestoreConflictFail(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := backupHandlers(t)
+
+	_, err := client.Restore(strings.NewReader(testBackup), RestoreOptions{OnConflict: ConflictFail})
+
+	assert.EqualError(t, err, "backup conflicts with existing role Power User, user BOB@bobloblawlaw.com")
+	assert.Empty(t, *requests)
+}
+
+func TestAims_RestoreInvalid(t *testing.T) {
+	var restoreTests = []struct {
+		backup  string
+		options RestoreOptions
+		err     string
+	}{
+		{`{"version": 2}`, RestoreOptions{}, "unsupported backup version 2"},
+		{`{"version": 1}`, RestoreOptions{OnConflict: "merge"}, `unknown conflict policy "merge"`},
+		{`{`, RestoreOptions{}, "error reading backup: unexpected EOF"},
+	}
+
+	for _, tt := range restoreTests {
+		_, err := client.Restore(strings.NewReader(tt.backup), tt.options)
+
+		assert.EqualError(t, err, tt.err)
+	}
+}
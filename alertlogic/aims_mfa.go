@@ -0,0 +1,119 @@
+package alertlogic
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// mfaIssuer is the issuer shown by authenticator apps for enrolled devices.
+	mfaIssuer = "Alert Logic"
+	// mfaSecretLength is the length in bytes of generated TOTP secrets.
+	mfaSecretLength = 20
+)
+
+// MFAEnrollment holds the TOTP secret and provisioning URI for enrolling a user's MFA device.
+type MFAEnrollment struct {
+	UserID string `json:"user_id"`
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// verifyMFARequest holds the MFA enrollment request data.
+type verifyMFARequest struct {
+	MfaURI   string   `json:"mfa_uri"`
+	MfaCodes []string `json:"mfa_codes"`
+}
+
+// EnrollMFA starts enrolling an MFA device for a user. It generates a new TOTP secret and
+// returns it along with an `otpauth://` provisioning URI that can be rendered as a QR code for
+// the user's authenticator app.
+// The device is not registered with Alert Logic until VerifyMFA is called with codes generated
+// from the returned enrollment.
+func (api *API) EnrollMFA(userId string) (MFAEnrollment, error) {
+	user, err := api.GetUserDetails(userId, false, false, false)
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	secretBytes := make([]byte, mfaSecretLength)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return MFAEnrollment{}, errors.Wrap(err, "error generating MFA secret")
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secretBytes)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", mfaIssuer)
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     fmt.Sprintf("/%s:%s", mfaIssuer, user.Email),
+		RawQuery: params.Encode(),
+	}
+
+	return MFAEnrollment{UserID: userId, Secret: secret, URI: uri.String()}, nil
+}
+
+// VerifyMFA completes an MFA enrollment started by EnrollMFA. `codes` are consecutive codes
+// generated by the user's authenticator app from the enrollment's secret.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-EnrollMFA
+func (api *API) VerifyMFA(userId string, enrollment MFAEnrollment, codes ...string) (int, error) {
+	if enrollment.URI == "" {
+		return 0, errors.New(errEmptyMFAURI)
+	}
+	if len(codes) == 0 {
+		return 0, errors.New(errEmptyMFACodes)
+	}
+
+	request := verifyMFARequest{MfaURI: enrollment.URI, MfaCodes: codes}
+
+	_, statusCode, err := api.makeRequest("POST", fmt.Sprintf("%s/%s/users/%s/mfa", aimsServicePath, api.AccountID, userId), nil, nil, request)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
+// RemoveMFA removes the enrolled MFA device from a user.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-DeleteMFA
+func (api *API) RemoveMFA(userId string) (int, error) {
+	_, statusCode, err := api.makeRequest("DELETE", fmt.Sprintf("%s/%s/users/%s/mfa", aimsServicePath, api.AccountID, userId), nil, nil, nil)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
+// ListUsersWithoutMFA lists all users in the account that do not have an MFA device enrolled.
+// This is useful for finding users that would be locked out before enabling
+// `AccountDetails.MfaRequired`. Notifications-only users are left out, since they cannot log in.
+func (api *API) ListUsersWithoutMFA() ([]User, error) {
+	users, err := api.ListUsers(false, false, false, "")
+	if err != nil {
+		return nil, err
+	}
+
+	withoutMFA := []User{}
+	for _, user := range users.Users {
+		if user.NotificationsOnly {
+			continue
+		}
+		if user.MfaEnabled == nil || !*user.MfaEnabled {
+			withoutMFA = append(withoutMFA, user)
+		}
+	}
+
+	return withoutMFA, nil
+}
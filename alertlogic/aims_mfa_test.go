@@ -0,0 +1,163 @@
+package alertlogic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	userMFAPath = fmt.Sprintf("/%s/%s/users/%s/mfa", aimsServicePath, testAccountId, testUserId)
+)
+
+func TestAims_EnrollMFA(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, `{"id": "715A4EC0-9833-4D6E-9C03-A537E3F98D23", "email": "bob@bobloblawlaw.com"}`)
+	})
+
+	enrollment, err := client.EnrollMFA(testUserId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, testUserId, enrollment.UserID)
+		assert.Len(t, enrollment.Secret, 32)
+
+		uri, err := url.Parse(enrollment.URI)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "otpauth", uri.Scheme)
+			assert.Equal(t, "totp", uri.Host)
+			assert.Equal(t, "/Alert Logic:bob@bobloblawlaw.com", uri.Path)
+			assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+			assert.Equal(t, "Alert Logic", uri.Query().Get("issuer"))
+		}
+	}
+}
+
+func TestAims_EnrollMFAUserError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := client.EnrollMFA(testUserId)
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), testNotFoundError)
+}
+
+func TestAims_VerifyMFA(t *testing.T) {
+	setup()
+	defer teardown()
+
+	enrollment := MFAEnrollment{
+		UserID: testUserId,
+		Secret: "JBSWY3DPEHPK3PXP",
+		URI:    "otpauth://totp/Alert%20Logic:bob@bobloblawlaw.com?issuer=Alert+Logic&secret=JBSWY3DPEHPK3PXP",
+	}
+
+	mux.HandleFunc(userMFAPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, fmt.Sprintf(`{"mfa_uri": %q, "mfa_codes": ["123456", "654321"]}`, enrollment.URI), string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.VerifyMFA(testUserId, enrollment, "123456", "654321")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_VerifyMFAError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	errorResponse := `{"error":"invalid_mfa_code"}`
+
+	mux.HandleFunc(userMFAPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, errorResponse)
+	})
+
+	respCode, err := client.VerifyMFA(testUserId, MFAEnrollment{URI: "otpauth://totp/x"}, "000000")
+
+	assert.Error(t, err)
+	assert.Equal(t, respCode, http.StatusBadRequest)
+	assert.Equal(t, err.Error(), fmt.Sprintf("error from makeRequest: %s", errorResponse))
+}
+
+func TestAims_VerifyMFAMissingValues(t *testing.T) {
+	_, err := client.VerifyMFA(testUserId, MFAEnrollment{}, "123456")
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyMFAURI)
+
+	_, err = client.VerifyMFA(testUserId, MFAEnrollment{URI: "otpauth://totp/x"})
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyMFACodes)
+}
+
+func TestAims_RemoveMFA(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(userMFAPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.RemoveMFA(testUserId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_ListUsersWithoutMFA(t *testing.T) {
+	setup()
+	defer teardown()
+
+	const response = `
+	{
+		"users": [
+			{"id": "1", "email": "enrolled@bobloblawlaw.com", "mfa_enabled": true},
+			{"id": "2", "email": "disabled@bobloblawlaw.com", "mfa_enabled": false},
+			{"id": "3", "email": "unknown@bobloblawlaw.com"},
+			{"id": "4", "email": "pager@bobloblawlaw.com", "mfa_enabled": false, "notifications_only": true}
+		]
+	}`
+
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, response)
+	})
+
+	mfaDisabled := false
+	want := []User{
+		{ID: "2", Email: "disabled@bobloblawlaw.com", MfaEnabled: &mfaDisabled},
+		{ID: "3", Email: "unknown@bobloblawlaw.com"},
+	}
+
+	users, err := client.ListUsersWithoutMFA()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, users, want)
+	}
+}
@@ -0,0 +1,181 @@
+package alertlogic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// permissionWildcard matches any value in a permission segment.
+	permissionWildcard = "*"
+	// permissionSeparator separates the segments of a permission string.
+	permissionSeparator = ":"
+)
+
+// PermissionRule is a single AIMS permission string, such as `*:own:get:*`, and its effect.
+// `Source` is the name of the role the rule came from, if known.
+type PermissionRule struct {
+	Permission string     `json:"permission"`
+	Effect     Permission `json:"effect"`
+	Source     string     `json:"source,omitempty"`
+}
+
+// matches reports whether the rule applies to `permission`. Each `:` separated segment of the
+// rule must equal the corresponding segment of `permission` or be `*`.
+func (r PermissionRule) matches(permission []string) bool {
+	segments := strings.Split(r.Permission, permissionSeparator)
+	if len(segments) != len(permission) {
+		return false
+	}
+
+	for i, segment := range segments {
+		if segment != permissionWildcard && segment != permission[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// specificity is the number of segments in the rule that are not wildcards.
+func (r PermissionRule) specificity() int {
+	n := 0
+	for _, segment := range strings.Split(r.Permission, permissionSeparator) {
+		if segment != permissionWildcard {
+			n++
+		}
+	}
+
+	return n
+}
+
+// PermissionDecision explains the result of evaluating a permission against a PermissionSet.
+// `Rule` is the rule that decided the result and is nil when no rule matched, in which case the
+// permission is not allowed. `Matches` holds every rule that matched.
+type PermissionDecision struct {
+	Permission string           `json:"permission"`
+	Allowed    bool             `json:"allowed"`
+	Rule       *PermissionRule  `json:"rule,omitempty"`
+	Matches    []PermissionRule `json:"matches,omitempty"`
+}
+
+// String returns a human-readable explanation of the decision.
+func (d PermissionDecision) String() string {
+	if d.Rule == nil {
+		return fmt.Sprintf("%s: denied, no rule matches", d.Permission)
+	}
+
+	source := ""
+	if d.Rule.Source != "" {
+		source = fmt.Sprintf(" from role %q", d.Rule.Source)
+	}
+
+	if d.Allowed {
+		return fmt.Sprintf("%s: allowed by %s%s", d.Permission, d.Rule.Permission, source)
+	}
+
+	return fmt.Sprintf("%s: denied by %s%s", d.Permission, d.Rule.Permission, source)
+}
+
+// PermissionSet evaluates AIMS permission strings offline. A permission is allowed when at least
+// one allowed rule matches it and no denied rule does; denied rules always take precedence over
+// allowed rules.
+type PermissionSet struct {
+	rules []PermissionRule
+}
+
+// NewPermissionSet creates a PermissionSet from a map of permission strings, such as
+// `Role.Permissions`.
+func NewPermissionSet(permissions map[string]Permission) PermissionSet {
+	set := PermissionSet{}
+	set.add(permissions, "")
+	set.sort()
+
+	return set
+}
+
+// NewPermissionSetFromList creates a PermissionSet from the result of GetUserPermissions.
+func NewPermissionSetFromList(permissionsList PermissionsList) PermissionSet {
+	set := PermissionSet{}
+	for _, permissions := range permissionsList.Permissions {
+		set.add(permissions, "")
+	}
+	set.sort()
+
+	return set
+}
+
+// NewPermissionSetFromRoles creates a PermissionSet from the permissions of all `roles`. Each
+// rule records the name of the role it came from.
+func NewPermissionSetFromRoles(roles ...Role) PermissionSet {
+	set := PermissionSet{}
+	for _, role := range roles {
+		set.add(role.Permissions, role.Name)
+	}
+	set.sort()
+
+	return set
+}
+
+// Rules returns all rules in the set.
+func (s PermissionSet) Rules() []PermissionRule {
+	rules := make([]PermissionRule, len(s.rules))
+	copy(rules, s.rules)
+
+	return rules
+}
+
+// Allowed reports whether `permission`, such as `aims:own:get:user`, is allowed.
+func (s PermissionSet) Allowed(permission string) bool {
+	return s.Evaluate(permission).Allowed
+}
+
+// Evaluate evaluates `permission`, such as `aims:own:get:user`, and explains which rule decided
+// the result. When several rules of the deciding effect match, the most specific one is reported.
+func (s PermissionSet) Evaluate(permission string) PermissionDecision {
+	decision := PermissionDecision{Permission: permission}
+	segments := strings.Split(permission, permissionSeparator)
+
+	for _, rule := range s.rules {
+		if !rule.matches(segments) {
+			continue
+		}
+
+		rule := rule
+		decision.Matches = append(decision.Matches, rule)
+
+		switch {
+		case rule.Effect == Denied && (decision.Rule == nil || decision.Allowed):
+			decision.Rule = &rule
+			decision.Allowed = false
+		case rule.Effect == Allowed && decision.Rule == nil:
+			decision.Rule = &rule
+			decision.Allowed = true
+		}
+	}
+
+	return decision
+}
+
+// add adds `permissions` to the set with `source` as their source.
+func (s *PermissionSet) add(permissions map[string]Permission, source string) {
+	for permission, effect := range permissions {
+		s.rules = append(s.rules, PermissionRule{Permission: permission, Effect: effect, Source: source})
+	}
+}
+
+// sort orders the rules from most to least specific so that Evaluate reports the most specific
+// deciding rule, with ties broken by permission string and source for stable results.
+func (s *PermissionSet) sort() {
+	sort.SliceStable(s.rules, func(i, j int) bool {
+		a, b := s.rules[i], s.rules[j]
+		if a.specificity() != b.specificity() {
+			return a.specificity() > b.specificity()
+		}
+		if a.Permission != b.Permission {
+			return a.Permission < b.Permission
+		}
+		return a.Source < b.Source
+	})
+}
@@ -0,0 +1,81 @@
+package alertlogic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAims_PermissionSetFromList(t *testing.T) {
+	set := NewPermissionSetFromList(PermissionsList{
+		Permissions: []map[string]Permission{
+			{"*:managed:*:*": Allowed},
+			{"aims:managed:update:role": Denied},
+			{"aims:managed:delete:role": Denied},
+		},
+	})
+
+	var permissionTests = []struct {
+		permission string
+		allowed    bool
+		rule       string
+	}{
+		{"aims:managed:get:user", true, "*:managed:*:*"},
+		{"aims:managed:update:role", false, "aims:managed:update:role"},
+		{"aims:managed:delete:role", false, "aims:managed:delete:role"},
+		{"aims:own:get:user", false, ""},
+		{"aims:managed:get", false, ""},
+	}
+
+	for _, tt := range permissionTests {
+		decision := set.Evaluate(tt.permission)
+
+		assert.Equal(t, tt.allowed, decision.Allowed, tt.permission)
+		assert.Equal(t, tt.allowed, set.Allowed(tt.permission), tt.permission)
+		if tt.rule == "" {
+			assert.Nil(t, decision.Rule, tt.permission)
+		} else if assert.NotNil(t, decision.Rule, tt.permission) {
+			assert.Equal(t, tt.rule, decision.Rule.Permission, tt.permission)
+		}
+	}
+}
+
+func TestAims_PermissionSetDeniedOverridesAllowed(t *testing.T) {
+	set := NewPermissionSet(map[string]Permission{
+		"aims:own:create:user": Allowed,
+		"aims:own:create:*":    Denied,
+		"*:own:*:*":            Allowed,
+	})
+
+	decision := set.Evaluate("aims:own:create:user")
+
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "aims:own:create:*", decision.Rule.Permission)
+	assert.Len(t, decision.Matches, 3)
+	assert.Equal(t, "aims:own:create:user: denied by aims:own:create:*", decision.String())
+}
+
+func TestAims_PermissionSetFromRoles(t *testing.T) {
+	set := NewPermissionSetFromRoles(
+		Role{Name: "Read Only", Permissions: map[string]Permission{"*:own:get:*": Allowed, "*:own:list:*": Allowed}},
+		Role{Name: "No Deployments", Permissions: map[string]Permission{"deployments:own:*:*": Denied}},
+	)
+
+	assert.Equal(t, `aims:own:get:user: allowed by *:own:get:* from role "Read Only"`, set.Evaluate("aims:own:get:user").String())
+	assert.Equal(t, `deployments:own:get:deployment: denied by deployments:own:*:* from role "No Deployments"`, set.Evaluate("deployments:own:get:deployment").String())
+	assert.Equal(t, "aims:own:delete:user: denied, no rule matches", set.Evaluate("aims:own:delete:user").String())
+	assert.Len(t, set.Rules(), 3)
+}
+
+func TestAims_PermissionSetMostSpecificRule(t *testing.T) {
+	set := NewPermissionSet(map[string]Permission{
+		"*:*:*:*":           Allowed,
+		"aims:own:get:*":    Allowed,
+		"aims:own:get:user": Allowed,
+	})
+
+	decision := set.Evaluate("aims:own:get:user")
+
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, "aims:own:get:user", decision.Rule.Permission)
+}
@@ -0,0 +1,329 @@
+package alertlogic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// RoleState is the desired state of an account's custom roles and who holds them.
+// Only users listed in `Assignments` are changed; each of them ends up with exactly the roles
+// listed for them. Custom roles that are not in `Roles` are left alone unless `PruneRoles` is
+// set.
+type RoleState struct {
+	Roles       []RoleDefinition `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Assignments []RoleAssignment `json:"assignments,omitempty" yaml:"assignments,omitempty"`
+	PruneRoles  bool             `json:"prune_roles,omitempty" yaml:"prune_roles,omitempty"`
+}
+
+// RoleDefinition is the desired definition of a custom role.
+type RoleDefinition struct {
+	Name        string                `json:"name" yaml:"name"`
+	Permissions map[string]Permission `json:"permissions" yaml:"permissions"`
+}
+
+// RoleAssignment is the desired set of roles, by name, for a user identified by email address
+// or username.
+type RoleAssignment struct {
+	User  string   `json:"user" yaml:"user"`
+	Roles []string `json:"roles" yaml:"roles"`
+}
+
+// RolePlanAction is a change made by a RolePlan.
+type RolePlanAction string
+
+const (
+	CreateRoleAction RolePlanAction = "create_role"
+	UpdateRoleAction RolePlanAction = "update_role"
+	DeleteRoleAction RolePlanAction = "delete_role"
+	GrantRoleAction  RolePlanAction = "grant_role"
+	RevokeRoleAction RolePlanAction = "revoke_role"
+)
+
+// RolePlanStep is a single change in a RolePlan. `RoleID` is empty for roles that are created by
+// an earlier step of the same plan. `Permissions` is only set for created or updated roles.
+type RolePlanStep struct {
+	Action      RolePlanAction        `json:"action"`
+	RoleName    string                `json:"role_name"`
+	RoleID      string                `json:"role_id,omitempty"`
+	User        string                `json:"user,omitempty"`
+	UserID      string                `json:"user_id,omitempty"`
+	Permissions map[string]Permission `json:"permissions,omitempty"`
+}
+
+// String returns a human-readable description of the step.
+func (s RolePlanStep) String() string {
+	switch s.Action {
+	case CreateRoleAction:
+		return fmt.Sprintf("+ create role %q with %d permissions", s.RoleName, len(s.Permissions))
+	case UpdateRoleAction:
+		return fmt.Sprintf("~ update role %q to %d permissions", s.RoleName, len(s.Permissions))
+	case DeleteRoleAction:
+		return fmt.Sprintf("- delete role %q", s.RoleName)
+	case GrantRoleAction:
+		return fmt.Sprintf("+ grant role %q to %s", s.RoleName, s.User)
+	case RevokeRoleAction:
+		return fmt.Sprintf("- revoke role %q from %s", s.RoleName, s.User)
+	}
+
+	return fmt.Sprintf("? %s %q", s.Action, s.RoleName)
+}
+
+// RolePlan is the set of changes needed to bring an account in line with a RoleState.
+type RolePlan struct {
+	Steps []RolePlanStep `json:"steps"`
+}
+
+// Empty reports whether the plan has no changes.
+func (p RolePlan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// String returns a human-readable description of the plan, one step per line.
+func (p RolePlan) String() string {
+	if p.Empty() {
+		return "no changes"
+	}
+
+	lines := make([]string, len(p.Steps))
+	for i, step := range p.Steps {
+		lines[i] = step.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// RolePlanStepError is a step of a RolePlan that failed to apply.
+type RolePlanStepError struct {
+	Step RolePlanStep
+	Err  error
+}
+
+// Error returns the error message including the failed step.
+func (e RolePlanStepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+// RoleApplyResult holds the outcome of applying a RolePlan.
+type RoleApplyResult struct {
+	Applied []RolePlanStep
+	Failed  []RolePlanStepError
+}
+
+// ParseRoleState parses a RoleState from a YAML or JSON document.
+func ParseRoleState(data []byte) (RoleState, error) {
+	var state RoleState
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return RoleState{}, errors.Wrap(err, "error parsing role state")
+	}
+
+	return state, nil
+}
+
+// PlanRoles compares `state` with the account's live roles and role assignments and returns the
+// changes needed to bring the account in line with it. Nothing is changed in the account.
+// Roles are created or updated first, then granted and revoked, and pruned roles are deleted
+// last.
+func (api *API) PlanRoles(state RoleState) (RolePlan, error) {
+	roles, err := api.ListRoles()
+	if err != nil {
+		return RolePlan{}, err
+	}
+
+	users, err := api.ListUsers(false, false, true, "")
+	if err != nil {
+		return RolePlan{}, err
+	}
+
+	liveRoles := map[string]Role{}
+	for _, role := range roles.Roles {
+		liveRoles[role.Name] = role
+	}
+
+	var plan RolePlan
+	desiredRoles := map[string]bool{}
+
+	definitions := append([]RoleDefinition{}, state.Roles...)
+	sort.SliceStable(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+
+	for _, definition := range definitions {
+		if definition.Name == "" {
+			return RolePlan{}, errors.New(errEmptyRoleName)
+		}
+		if desiredRoles[definition.Name] {
+			return RolePlan{}, errors.Errorf("role %q is defined more than once", definition.Name)
+		}
+		desiredRoles[definition.Name] = true
+
+		live, exists := liveRoles[definition.Name]
+		switch {
+		case !exists:
+			plan.Steps = append(plan.Steps, RolePlanStep{Action: CreateRoleAction, RoleName: definition.Name, Permissions: definition.Permissions})
+		case samePermissions(live.Permissions, definition.Permissions):
+		case live.Global:
+			return RolePlan{}, errors.Errorf("role %q is a global role and cannot be changed", definition.Name)
+		default:
+			plan.Steps = append(plan.Steps, RolePlanStep{Action: UpdateRoleAction, RoleName: definition.Name, RoleID: live.ID, Permissions: definition.Permissions})
+		}
+	}
+
+	roleNames := map[string]string{}
+	for _, role := range roles.Roles {
+		roleNames[role.ID] = role.Name
+	}
+
+	for _, assignment := range state.Assignments {
+		user, err := findUser(users.Users, assignment.User)
+		if err != nil {
+			return RolePlan{}, err
+		}
+
+		desired := map[string]bool{}
+		for _, name := range assignment.Roles {
+			if _, exists := liveRoles[name]; !exists && !desiredRoles[name] {
+				return RolePlan{}, errors.Errorf("role %q assigned to %s does not exist", name, assignment.User)
+			}
+			desired[name] = true
+		}
+
+		held := map[string]bool{}
+		if user.RoleIds != nil {
+			for _, roleId := range *user.RoleIds {
+				held[roleName(roleNames, roleId)] = true
+			}
+		}
+
+		for _, name := range sortedKeys(desired) {
+			if !held[name] {
+				plan.Steps = append(plan.Steps, RolePlanStep{Action: GrantRoleAction, RoleName: name, RoleID: liveRoles[name].ID, User: assignment.User, UserID: user.ID})
+			}
+		}
+
+		if user.RoleIds != nil {
+			for _, roleId := range *user.RoleIds {
+				name := roleName(roleNames, roleId)
+				if !desired[name] {
+					plan.Steps = append(plan.Steps, RolePlanStep{Action: RevokeRoleAction, RoleName: name, RoleID: roleId, User: assignment.User, UserID: user.ID})
+				}
+			}
+		}
+	}
+
+	if state.PruneRoles {
+		for _, role := range roles.Roles {
+			if !role.Global && !desiredRoles[role.Name] {
+				plan.Steps = append(plan.Steps, RolePlanStep{Action: DeleteRoleAction, RoleName: role.Name, RoleID: role.ID})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// ApplyRolePlan applies the steps of `plan` in order using CreateRole, UpdateRole, GrantUserRole,
+// RevokeUserRole and DeleteRole. A failed step does not stop the remaining steps, except that
+// grants of a role that failed to be created are also failed. The returned error is non-nil if
+// any step failed, and `RoleApplyResult.Failed` lists each of them.
+func (api *API) ApplyRolePlan(plan RolePlan) (RoleApplyResult, error) {
+	var result RoleApplyResult
+	createdRoles := map[string]string{}
+
+	for _, step := range plan.Steps {
+		err := api.applyRolePlanStep(&step, createdRoles)
+		if err != nil {
+			result.Failed = append(result.Failed, RolePlanStepError{Step: step, Err: err})
+			continue
+		}
+		result.Applied = append(result.Applied, step)
+	}
+
+	if len(result.Failed) > 0 {
+		return result, errors.Errorf("%d of %d role plan steps failed", len(result.Failed), len(plan.Steps))
+	}
+
+	return result, nil
+}
+
+// applyRolePlanStep applies a single step, recording the IDs of created roles in `createdRoles`
+// and resolving them for later steps.
+func (api *API) applyRolePlanStep(step *RolePlanStep, createdRoles map[string]string) error {
+	if step.RoleID == "" && step.Action != CreateRoleAction {
+		roleId, ok := createdRoles[step.RoleName]
+		if !ok {
+			return errors.Errorf("role %q was not created", step.RoleName)
+		}
+		step.RoleID = roleId
+	}
+
+	var err error
+	switch step.Action {
+	case CreateRoleAction:
+		var role Role
+		role, err = api.CreateRole(CreateRoleRequest{Name: step.RoleName, Permissions: step.Permissions})
+		if err == nil {
+			step.RoleID = role.ID
+			createdRoles[step.RoleName] = role.ID
+		}
+	case UpdateRoleAction:
+		_, err = api.UpdateRole(step.RoleID, UpdateRoleRequest{Permissions: step.Permissions})
+	case DeleteRoleAction:
+		_, err = api.DeleteRole(step.RoleID)
+	case GrantRoleAction:
+		_, err = api.GrantUserRole(step.UserID, step.RoleID)
+	case RevokeRoleAction:
+		_, err = api.RevokeUserRole(step.UserID, step.RoleID)
+	default:
+		err = errors.Errorf("unknown role plan action %q", step.Action)
+	}
+
+	return err
+}
+
+// roleName returns the name of the role with `roleId`, or the ID itself if the role is unknown.
+func roleName(roleNames map[string]string, roleId string) string {
+	if name, ok := roleNames[roleId]; ok {
+		return name
+	}
+
+	return roleId
+}
+
+// findUser finds the user in `users` whose email address or username matches `user`.
+func findUser(users []User, user string) (User, error) {
+	for _, u := range users {
+		if strings.EqualFold(u.Email, user) || strings.EqualFold(u.Username, user) {
+			return u, nil
+		}
+	}
+
+	return User{}, errors.Errorf("user %s does not exist", user)
+}
+
+// samePermissions reports whether two permission maps are equal.
+func samePermissions(a map[string]Permission, b map[string]Permission) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for permission, effect := range a {
+		if other, ok := b[permission]; !ok || other != effect {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortedKeys returns the keys of `m` in sorted order.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
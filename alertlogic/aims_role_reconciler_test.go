@@ -0,0 +1,248 @@
+package alertlogic
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testReadOnlyRoleId = "F578CCE5-9574-4489-BF05-A04075838DE3"
+	testPowerRoleId    = "2A33175D-86EF-44B5-AA39-C9549F6306DF"
+	testAdminRoleId    = "A0D04F4F-6F53-4B0A-8B36-FAF1B4A85D2A"
+	testNewRoleId      = "C3C8A0B6-4A55-4C7F-9D7B-2F4A2B9F7F11"
+)
+
+const roleStateYAML = `
+roles:
+  - name: Read Only
+    permissions:
+      "*:own:get:*": allowed
+      "*:own:list:*": allowed
+  - name: Auditors
+    permissions:
+      "*:own:get:*": allowed
+  - name: Power User
+    permissions:
+      "*:own:*:*": allowed
+assignments:
+  - user: bob@bobloblawlaw.com
+    roles: [Auditors, Read Only]
+`
+
+// roleReconcilerHandlers serves the live roles and users for reconciler tests and records every
+// change request as "METHOD path".
+func roleReconcilerHandlers(t *testing.T, failPaths ...string) *[]string {
+	var mu sync.Mutex
+	requests := []string{}
+
+	record := func(w http.ResponseWriter, r *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests = append(requests, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+		for _, failPath := range failPaths {
+			if strings.HasSuffix(r.URL.Path, failPath) {
+				w.WriteHeader(http.StatusInternalServerError)
+				return false
+			}
+		}
+		return true
+	}
+
+	mux.HandleFunc(listRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			if record(w, r) {
+				fmt.Fprintf(w, `{"id": %q, "name": "Auditors"}`, testNewRoleId)
+			}
+			return
+		}
+
+		fmt.Fprintf(w, `{"roles": [
+			{"id": %q, "name": "Read Only", "permissions": {"*:own:get:*": "allowed", "*:own:list:*": "allowed"}},
+			{"id": %q, "name": "Power User", "permissions": {"*:own:*:*": "allowed", "aims:own:create:*": "denied"}},
+			{"id": %q, "name": "Administrator", "permissions": {"*:*:*:*": "allowed"}, "global": true},
+			{"id": "OLD", "name": "Old Role", "permissions": {}}
+		]}`, testReadOnlyRoleId, testPowerRoleId, testAdminRoleId)
+	})
+
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("include_role_ids"))
+		fmt.Fprintf(w, `{"users": [
+			{"id": %q, "email": "bob@bobloblawlaw.com", "username": "bob", "role_ids": [%q, %q]}
+		]}`, testUserId, testReadOnlyRoleId, testAdminRoleId)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/roles/", aimsServicePath, testAccountId), func(w http.ResponseWriter, r *http.Request) {
+		if record(w, r) {
+			if r.Method == "DELETE" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		}
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/users/%s/roles/", aimsServicePath, testAccountId, testUserId), func(w http.ResponseWriter, r *http.Request) {
+		if record(w, r) {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	return &requests
+}
+
+func TestAims_ParseRoleState(t *testing.T) {
+	state, err := ParseRoleState([]byte(roleStateYAML))
+
+	if assert.NoError(t, err) {
+		assert.Len(t, state.Roles, 3)
+		assert.Equal(t, map[string]Permission{"*:own:get:*": Allowed}, state.Roles[1].Permissions)
+		assert.Equal(t, []RoleAssignment{{User: testEmail, Roles: []string{"Auditors", "Read Only"}}}, state.Assignments)
+	}
+
+	jsonState, err := ParseRoleState([]byte(`{"roles": [{"name": "Auditors", "permissions": {"*:own:get:*": "allowed"}}], "prune_roles": true}`))
+
+	if assert.NoError(t, err) {
+		assert.True(t, jsonState.PruneRoles)
+		assert.Equal(t, "Auditors", jsonState.Roles[0].Name)
+	}
+
+	_, err = ParseRoleState([]byte("roles: ["))
+	assert.Error(t, err)
+}
+
+func TestAims_PlanRoles(t *testing.T) {
+	setup()
+	defer teardown()
+
+	roleReconcilerHandlers(t)
+
+	state, _ := ParseRoleState([]byte(roleStateYAML))
+	state.PruneRoles = true
+
+	plan, err := client.PlanRoles(state)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.Join([]string{
+			`+ create role "Auditors" with 1 permissions`,
+			`~ update role "Power User" to 1 permissions`,
+			`+ grant role "Auditors" to bob@bobloblawlaw.com`,
+			`- revoke role "Administrator" from bob@bobloblawlaw.com`,
+			`- delete role "Old Role"`,
+		}, "\n"), plan.String())
+		assert.Equal(t, testPowerRoleId, plan.Steps[1].RoleID)
+		assert.Equal(t, "", plan.Steps[2].RoleID)
+		assert.Equal(t, testUserId, plan.Steps[2].UserID)
+	}
+}
+
+func TestAims_PlanRolesNoChanges(t *testing.T) {
+	setup()
+	defer teardown()
+
+	roleReconcilerHandlers(t)
+
+	plan, err := client.PlanRoles(RoleState{
+		Roles:       []RoleDefinition{{Name: "Read Only", Permissions: map[string]Permission{"*:own:get:*": Allowed, "*:own:list:*": Allowed}}},
+		Assignments: []RoleAssignment{{User: "BOB", Roles: []string{"Read Only", "Administrator"}}},
+	})
+
+	if assert.NoError(t, err) {
+		assert.True(t, plan.Empty())
+		assert.Equal(t, "no changes", plan.String())
+	}
+}
+
+func TestAims_PlanRolesErrors(t *testing.T) {
+	var planTests = []struct {
+		state RoleState
+		err   string
+	}{
+		{
+			RoleState{Assignments: []RoleAssignment{{User: "nobody@bobloblawlaw.com"}}},
+			"user nobody@bobloblawlaw.com does not exist",
+		},
+		{
+			RoleState{Assignments: []RoleAssignment{{User: testEmail, Roles: []string{"Missing"}}}},
+			`role "Missing" assigned to bob@bobloblawlaw.com does not exist`,
+		},
+		{
+			RoleState{Roles: []RoleDefinition{{Name: "Administrator"}}},
+			`role "Administrator" is a global role and cannot be changed`,
+		},
+		{
+			RoleState{Roles: []RoleDefinition{{Name: "Twice"}, {Name: "Twice"}}},
+			`role "Twice" is defined more than once`,
+		},
+		{
+			RoleState{Roles: []RoleDefinition{{}}},
+			errEmptyRoleName,
+		},
+	}
+
+	for _, tt := range planTests {
+		setup()
+		roleReconcilerHandlers(t)
+
+		_, err := client.PlanRoles(tt.state)
+
+		assert.EqualError(t, err, tt.err)
+		teardown()
+	}
+}
+
+func TestAims_ApplyRolePlan(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := roleReconcilerHandlers(t)
+
+	state, _ := ParseRoleState([]byte(roleStateYAML))
+	plan, err := client.PlanRoles(state)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result, err := client.ApplyRolePlan(plan)
+
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Applied, 4)
+		assert.Empty(t, result.Failed)
+		assert.Equal(t, testNewRoleId, result.Applied[2].RoleID)
+		assert.Equal(t, []string{
+			fmt.Sprintf("POST %s", listRolesPath),
+			fmt.Sprintf("POST /%s/%s/roles/%s", aimsServicePath, testAccountId, testPowerRoleId),
+			fmt.Sprintf("PUT /%s/%s/users/%s/roles/%s", aimsServicePath, testAccountId, testUserId, testNewRoleId),
+			fmt.Sprintf("DELETE /%s/%s/users/%s/roles/%s", aimsServicePath, testAccountId, testUserId, testAdminRoleId),
+		}, *requests)
+	}
+}
+
+func TestAims_ApplyRolePlanPartialFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	roleReconcilerHandlers(t, "/roles", testPowerRoleId)
+
+	state, _ := ParseRoleState([]byte(roleStateYAML))
+	plan, err := client.PlanRoles(state)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result, err := client.ApplyRolePlan(plan)
+
+	assert.EqualError(t, err, "3 of 4 role plan steps failed")
+	assert.Len(t, result.Applied, 1)
+	assert.Equal(t, RevokeRoleAction, result.Applied[0].Action)
+	if assert.Len(t, result.Failed, 3) {
+		assert.Equal(t, CreateRoleAction, result.Failed[0].Step.Action)
+		assert.Equal(t, UpdateRoleAction, result.Failed[1].Step.Action)
+		assert.Equal(t, `+ grant role "Auditors" to bob@bobloblawlaw.com: role "Auditors" was not created`, result.Failed[2].Error())
+	}
+}
@@ -30,6 +30,47 @@ const (
 	Denied  Permission = "denied"
 )
 
+// CreateRoleRequest holds the role create request data.
+type CreateRoleRequest struct {
+	Name        string                `json:"name,omitempty"`
+	Permissions map[string]Permission `json:"permissions,omitempty"`
+}
+
+// UpdateRoleRequest holds the role update request data.
+type UpdateRoleRequest = CreateRoleRequest
+
+// CreateRole creates a new role in the account.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Role_Resources-CreateRole
+func (api *API) CreateRole(role CreateRoleRequest) (Role, error) {
+	if role.Name == "" {
+		return Role{}, errors.New(errEmptyRoleName)
+	}
+
+	return api.modifyRole(fmt.Sprintf("%s/%s/roles", aimsServicePath, api.AccountID), role)
+}
+
+// UpdateRole updates a role in the account. Only the name and permissions that are set in
+// `role` are changed.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Role_Resources-UpdateRole
+func (api *API) UpdateRole(roleId string, role UpdateRoleRequest) (Role, error) {
+	return api.modifyRole(fmt.Sprintf("%s/%s/roles/%s", aimsServicePath, api.AccountID, roleId), role)
+}
+
+// DeleteRole deletes a role from the account.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Role_Resources-DeleteRole
+func (api *API) DeleteRole(roleId string) (int, error) {
+	_, statusCode, err := api.makeRequest("DELETE", fmt.Sprintf("%s/%s/roles/%s", aimsServicePath, api.AccountID, roleId), nil, nil, nil)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
 // GetRoleDetails retrieves a role's details.
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_Role_Resources-GetRole
@@ -74,6 +115,22 @@ func (api *API) getRole(path string) (Role, error) {
 	return r, nil
 }
 
+// modifyRole holds shared logic for creating or updating a Role.
+func (api *API) modifyRole(path string, role CreateRoleRequest) (Role, error) {
+	res, _, err := api.makeRequest("POST", path, nil, nil, role)
+	if err != nil {
+		return Role{}, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r Role
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return Role{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
 // getRoles holds shared logic for retrieving multiple Roles from the API.
 func (api *API) getRoles(path string) (RolesList, error) {
 	res, _, err := api.makeRequest("GET", path, nil, nil, nil)
@@ -2,6 +2,7 @@ package alertlogic
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"testing"
 
@@ -310,3 +311,99 @@ func TestAims_GetGlobalRoleDetails(t *testing.T) {
 		assert.Equal(t, roles, want)
 	}
 }
+
+func TestAims_CreateRole(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(listRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name": "Auditors", "permissions": {"*:own:get:*": "allowed"}}`, string(body))
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"id": %q, "account_id": %q, "name": "Auditors", "permissions": {"*:own:get:*": "allowed"}, "version": 1}`, testRoleId, testAccountId)
+	})
+
+	want := Role{
+		ID:          testRoleId,
+		AccountID:   testAccountId,
+		Name:        "Auditors",
+		Permissions: map[string]Permission{"*:own:get:*": Allowed},
+		Version:     1,
+	}
+
+	role, err := client.CreateRole(CreateRoleRequest{Name: "Auditors", Permissions: map[string]Permission{"*:own:get:*": Allowed}})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, role, want)
+	}
+}
+
+func TestAims_CreateRoleMissingName(t *testing.T) {
+	_, err := client.CreateRole(CreateRoleRequest{})
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyRoleName)
+}
+
+func TestAims_UpdateRole(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getRoleDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"permissions": {"*:own:list:*": "allowed"}}`, string(body))
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprintf(w, `{"id": %q, "name": "Auditors", "permissions": {"*:own:list:*": "allowed"}, "version": 2}`, testRoleId)
+	})
+
+	want := Role{
+		ID:          testRoleId,
+		Name:        "Auditors",
+		Permissions: map[string]Permission{"*:own:list:*": Allowed},
+		Version:     2,
+	}
+
+	role, err := client.UpdateRole(testRoleId, UpdateRoleRequest{Permissions: map[string]Permission{"*:own:list:*": Allowed}})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, role, want)
+	}
+}
+
+func TestAims_DeleteRole(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getRoleDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.DeleteRole(testRoleId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_DeleteRoleError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getRoleDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method, "Expected method 'DELETE', got %s", r.Method)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	respCode, err := client.DeleteRole(testRoleId)
+
+	assert.Error(t, err)
+	assert.Equal(t, respCode, http.StatusNotFound)
+	assert.Equal(t, err.Error(), testNotFoundError)
+}
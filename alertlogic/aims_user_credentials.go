@@ -0,0 +1,152 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// ChangePasswordRequest holds the change password request data.
+type ChangePasswordRequest struct {
+	Email           string `json:"email"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// InitiatePasswordResetRequest holds the initiate password reset request data.
+type InitiatePasswordResetRequest struct {
+	Email    string `json:"email"`
+	ReturnTo string `json:"return_to,omitempty"`
+}
+
+// ResetPasswordRequest holds the reset password request data.
+type ResetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// setUserPasswordRequest holds the data sent when an administrator sets a user's password.
+type setUserPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// ChangePassword changes the password of the user identified by `email`. The user's current
+// password is required.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-ChangePassword
+func (api *API) ChangePassword(email string, currentPassword string, newPassword string) (int, error) {
+	if email == "" || currentPassword == "" || newPassword == "" {
+		return 0, errors.New(errEmptyChangePassword)
+	}
+
+	request := ChangePasswordRequest{
+		Email:           email,
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	}
+
+	_, statusCode, err := api.makeRequest("POST", fmt.Sprintf("%s/change_password", aimsServicePath), nil, nil, request)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
+// InitiatePasswordReset emails the user identified by `email` a link to reset their password.
+// `returnTo` is the URL the link in the email points to and may be empty to use the Alert Logic
+// console.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-InitiatePasswordReset
+func (api *API) InitiatePasswordReset(email string, returnTo string) (int, error) {
+	if email == "" {
+		return 0, errors.New(errEmptyEmail)
+	}
+
+	request := InitiatePasswordResetRequest{Email: email, ReturnTo: returnTo}
+
+	_, statusCode, err := api.makeRequest("POST", fmt.Sprintf("%s/reset_password", aimsServicePath), nil, nil, request)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
+// ResetPasswordWithToken sets a new password using the token from a password reset email sent
+// by InitiatePasswordReset.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-ResetPasswordWithToken
+func (api *API) ResetPasswordWithToken(token string, password string) (int, error) {
+	if token == "" || password == "" {
+		return 0, errors.New(errEmptyTokenOrPassword)
+	}
+
+	request := ResetPasswordRequest{Password: password}
+
+	_, statusCode, err := api.makeRequest("PUT", fmt.Sprintf("%s/reset_password/%s", aimsServicePath, url.PathEscape(token)), nil, nil, request)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
+// SetUserPassword sets a user's password without requiring their current password.
+// If true, `oneTimePassword` will set the user's password as a one-time password and require them
+// to supply a new password upon next login.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-UpdateUser
+func (api *API) SetUserPassword(userId string, password string, oneTimePassword bool) (User, error) {
+	if password == "" {
+		return User{}, errors.New(errEmptyPassword)
+	}
+
+	var params map[string]string
+	if oneTimePassword {
+		params = map[string]string{"one_time_password": "true"}
+	}
+
+	res, _, err := api.makeRequest("POST", fmt.Sprintf("%s/%s/users/%s", aimsServicePath, api.AccountID, userId), nil, params, setUserPasswordRequest{Password: password})
+
+	if err != nil {
+		return User{}, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r User
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return User{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// LockUser locks a user, preventing them from logging in until they are unlocked.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-LockUser
+func (api *API) LockUser(userId string) (int, error) {
+	return api.userLock(userId, "lock")
+}
+
+// UnlockUser unlocks a user that was locked by LockUser or by too many failed login attempts.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-UnlockUser
+func (api *API) UnlockUser(userId string) (int, error) {
+	return api.userLock(userId, "unlock")
+}
+
+// userLock has shared functionality for locking or unlocking users.
+func (api *API) userLock(userId string, action string) (int, error) {
+	_, statusCode, err := api.makeRequest("POST", fmt.Sprintf("%s/%s/users/%s/%s", aimsServicePath, api.AccountID, userId, action), nil, nil, nil)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
@@ -0,0 +1,224 @@
+package alertlogic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testResetToken = "9f3b1f0a2c"
+)
+
+var (
+	changePasswordPath         = fmt.Sprintf("/%s/change_password", aimsServicePath)
+	initiatePasswordResetPath  = fmt.Sprintf("/%s/reset_password", aimsServicePath)
+	resetPasswordWithTokenPath = fmt.Sprintf("/%s/reset_password/%s", aimsServicePath, testResetToken)
+	setUserPasswordPath        = fmt.Sprintf("/%s/%s/users/%s", aimsServicePath, testAccountId, testUserId)
+	lockUserPath               = fmt.Sprintf("/%s/%s/users/%s/lock", aimsServicePath, testAccountId, testUserId)
+	unlockUserPath             = fmt.Sprintf("/%s/%s/users/%s/unlock", aimsServicePath, testAccountId, testUserId)
+)
+
+func TestAims_ChangePassword(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(changePasswordPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"email": "bob@bobloblawlaw.com", "current_password": "old", "new_password": "new"}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.ChangePassword(testEmail, "old", "new")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_ChangePasswordError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	errorResponse := `{"error":"invalid_password"}`
+
+	mux.HandleFunc(changePasswordPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, errorResponse)
+	})
+
+	respCode, err := client.ChangePassword(testEmail, "old", "new")
+
+	assert.Error(t, err)
+	assert.Equal(t, respCode, http.StatusBadRequest)
+	assert.Equal(t, err.Error(), fmt.Sprintf("error from makeRequest: %s", errorResponse))
+}
+
+func TestAims_ChangePasswordMissingValues(t *testing.T) {
+	_, err := client.ChangePassword(testEmail, "", "new")
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyChangePassword)
+}
+
+func TestAims_InitiatePasswordReset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(initiatePasswordResetPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"email": "bob@bobloblawlaw.com", "return_to": "https://example.com/reset"}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.InitiatePasswordReset(testEmail, "https://example.com/reset")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_InitiatePasswordResetMissingEmail(t *testing.T) {
+	_, err := client.InitiatePasswordReset("", "")
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyEmail)
+}
+
+func TestAims_ResetPasswordWithToken(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(resetPasswordWithTokenPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"password": "new"}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.ResetPasswordWithToken(testResetToken, "new")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_ResetPasswordWithTokenMissingValues(t *testing.T) {
+	_, err := client.ResetPasswordWithToken("", "new")
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyTokenOrPassword)
+}
+
+func TestAims_SetUserPassword(t *testing.T) {
+	setup()
+	defer teardown()
+
+	const response = `
+	{
+		"id": "715A4EC0-9833-4D6E-9C03-A537E3F98D23",
+		"account_id": "12345678",
+		"name": "Bob Loblaw",
+		"email": "bob@bobloblawlaw.com",
+		"active": true,
+		"version": 2
+	}`
+
+	var oneTimePassword []string
+	mux.HandleFunc(setUserPasswordPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"password": "temporary"}`, string(body))
+		oneTimePassword = append(oneTimePassword, r.URL.Query().Get("one_time_password"))
+
+		w.Header().Set("content-type", "application/json")
+		fmt.Fprint(w, response)
+	})
+
+	want := User{
+		ID:        testUserId,
+		AccountID: testAccountId,
+		Name:      testUserFullName,
+		Email:     testEmail,
+		Active:    true,
+		Version:   2,
+	}
+
+	user, err := client.SetUserPassword(testUserId, "temporary", true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, user, want)
+	}
+
+	_, err = client.SetUserPassword(testUserId, "temporary", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"true", ""}, oneTimePassword)
+	}
+}
+
+func TestAims_SetUserPasswordMissingPassword(t *testing.T) {
+	_, err := client.SetUserPassword(testUserId, "", true)
+
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), errEmptyPassword)
+}
+
+func TestAims_LockUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(lockUserPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.LockUser(testUserId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_UnlockUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(unlockUserPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	respCode, err := client.UnlockUser(testUserId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, respCode, http.StatusNoContent)
+	}
+}
+
+func TestAims_UnlockUserError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(unlockUserPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	respCode, err := client.UnlockUser(testUserId)
+
+	assert.Error(t, err)
+	assert.Equal(t, respCode, http.StatusNotFound)
+	assert.Equal(t, err.Error(), testNotFoundError)
+}
@@ -0,0 +1,178 @@
+package alertlogic
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// OffboardUserOptions control how OffboardUser removes a user.
+// By default the user is deleted. If `Deactivate` is set the user is deactivated instead, which
+// keeps their history and allows them to be reactivated later.
+type OffboardUserOptions struct {
+	Deactivate bool
+}
+
+// OffboardReport records what OffboardUser found and did. `Roles` and `AccessKeys` are the
+// user's roles and access keys before offboarding started.
+// `Verified` is true only if a final lookup confirmed that the user is gone, or deactivated with
+// no roles or access keys left.
+type OffboardReport struct {
+	User              User        `json:"user"`
+	Roles             []Role      `json:"roles"`
+	AccessKeys        []AccessKey `json:"access_keys"`
+	RevokedRoleIds    []string    `json:"revoked_role_ids"`
+	DeletedAccessKeys []string    `json:"deleted_access_keys"`
+	Deleted           bool        `json:"deleted"`
+	Deactivated       bool        `json:"deactivated"`
+	Verified          bool        `json:"verified"`
+	Errors            []string    `json:"errors,omitempty"`
+}
+
+// OffboardUser removes a user's access to the account. `user` is a user ID, email address or
+// username.
+// The user's roles and access keys are recorded, every role is revoked and every access key is
+// deleted, and then the user is deleted or, with `OffboardUserOptions.Deactivate`, deactivated.
+// Because DeleteUser succeeds even for unknown user IDs, the user is looked up again afterwards
+// to verify the result.
+// A failed step does not stop the remaining steps. The returned error is non-nil if the user
+// could not be found, any step failed or the result could not be verified, and the report
+// records everything that was done.
+func (api *API) OffboardUser(user string, options OffboardUserOptions) (OffboardReport, error) {
+	found, err := api.findAccountUser(user)
+	if err != nil {
+		return OffboardReport{}, err
+	}
+
+	report := OffboardReport{
+		User:              found,
+		RevokedRoleIds:    []string{},
+		DeletedAccessKeys: []string{},
+	}
+
+	roles, err := api.GetAssignedRoles(found.ID)
+	if err != nil {
+		return report, errors.Wrap(err, "error listing user roles")
+	}
+	report.Roles = roles.Roles
+
+	accessKeys, err := api.ListAccessKeys(found.ID)
+	if err != nil {
+		return report, errors.Wrap(err, "error listing user access keys")
+	}
+	report.AccessKeys = accessKeys.AccessKeys
+
+	for _, role := range report.Roles {
+		if _, err := api.RevokeUserRole(found.ID, role.ID); err != nil {
+			report.fail(errors.Wrapf(err, "error revoking role %q", role.Name))
+			continue
+		}
+		report.RevokedRoleIds = append(report.RevokedRoleIds, role.ID)
+	}
+
+	for _, accessKey := range report.AccessKeys {
+		if _, err := api.DeleteAccessKey(found.ID, accessKey.AccessKeyID); err != nil {
+			report.fail(errors.Wrapf(err, "error deleting access key %s", accessKey.AccessKeyID))
+			continue
+		}
+		report.DeletedAccessKeys = append(report.DeletedAccessKeys, accessKey.AccessKeyID)
+	}
+
+	if options.Deactivate {
+		if _, err := api.DeactivateUser(found.ID); err != nil {
+			report.fail(errors.Wrap(err, "error deactivating user"))
+		} else {
+			report.Deactivated = true
+		}
+	} else {
+		if _, err := api.DeleteUser(found.ID); err != nil {
+			report.fail(errors.Wrap(err, "error deleting user"))
+		} else {
+			report.Deleted = true
+		}
+	}
+
+	if err := api.verifyOffboarded(found.ID, options.Deactivate); err != nil {
+		report.fail(err)
+	} else {
+		report.Verified = true
+	}
+
+	if len(report.Errors) > 0 {
+		return report, errors.Errorf("offboarding user %s did not complete: %s", user, strings.Join(report.Errors, "; "))
+	}
+
+	return report, nil
+}
+
+// fail records a failed offboarding step.
+func (r *OffboardReport) fail(err error) {
+	r.Errors = append(r.Errors, err.Error())
+}
+
+// verifyOffboarded looks a user up again to confirm they were deleted, or deactivated with no
+// roles or access keys.
+func (api *API) verifyOffboarded(userId string, deactivated bool) error {
+	user, err := api.GetUserDetails(userId, true, false, true)
+	if isNotFound(err) {
+		if deactivated {
+			return errors.New("user no longer exists after deactivation")
+		}
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error verifying user")
+	}
+
+	if !deactivated {
+		return errors.New("user still exists after deletion")
+	}
+	if user.Active {
+		return errors.New("user is still active after deactivation")
+	}
+	if user.RoleIds != nil && len(*user.RoleIds) > 0 {
+		return errors.Errorf("user still holds %d roles", len(*user.RoleIds))
+	}
+	if user.AccessKeys != nil && len(*user.AccessKeys) > 0 {
+		return errors.Errorf("user still has %d access keys", len(*user.AccessKeys))
+	}
+
+	return nil
+}
+
+// findAccountUser finds a user in the account by ID, email address or username.
+func (api *API) findAccountUser(user string) (User, error) {
+	if user == "" {
+		return User{}, errors.New(errEmptyUser)
+	}
+
+	if strings.Contains(user, "@") {
+		users, err := api.ListUsersByEmail(user, false, false, false)
+		if err != nil && !isNotFound(err) {
+			return User{}, err
+		}
+		for _, u := range users.Users {
+			if u.AccountID == "" || u.AccountID == api.AccountID {
+				return u, nil
+			}
+		}
+	}
+
+	found, err := api.GetUserDetails(user, false, false, false)
+	if err == nil {
+		return found, nil
+	}
+	if !isNotFound(err) {
+		return User{}, err
+	}
+
+	found, err = api.GetUserDetailsByUsername(user, false, false, false)
+	if err == nil && (found.AccountID == "" || found.AccountID == api.AccountID) {
+		return found, nil
+	}
+	if err != nil && !isNotFound(err) {
+		return User{}, err
+	}
+
+	return User{}, errors.Errorf("user %s does not exist", user)
+}
@@ -0,0 +1,175 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// offboardHandlers serves a user with one role and one access key and records every change
+// request as "METHOD path". Revoking the role fails if `failRevoke` is set.
+func offboardHandlers(t *testing.T, failRevoke bool) *[]string {
+	requests := []string{}
+	deleted := false
+	active := true
+	roleIds := fmt.Sprintf("[%q]", testRoleId)
+
+	mux.HandleFunc(listUsersByEmailPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"users": [
+			{"id": "OTHER", "account_id": %q, "email": "bob@bobloblawlaw.com"},
+			{"id": %q, "account_id": %q, "email": "bob@bobloblawlaw.com"}
+		]}`, testRelatedAccountId, testUserId, testAccountId)
+	})
+
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "DELETE":
+			requests = append(requests, "DELETE "+r.URL.Path)
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		case "POST":
+			var update map[string]bool
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			requests = append(requests, fmt.Sprintf("POST %s active=%t", r.URL.Path, update["active"]))
+			active = update["active"]
+			fmt.Fprintf(w, `{"id": %q, "active": %t}`, testUserId, active)
+		default:
+			if deleted {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, `{"id": %q, "account_id": %q, "email": "bob@bobloblawlaw.com", "active": %t, "role_ids": %s, "access_keys": []}`, testUserId, testAccountId, active, roleIds)
+		}
+	})
+
+	mux.HandleFunc(getAssignedRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"roles": [{"id": %q, "name": "Read Only"}]}`, testRoleId)
+	})
+
+	mux.HandleFunc(revokeUserRolePath, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "DELETE "+r.URL.Path)
+		if failRevoke {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		roleIds = "[]"
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc(accessKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, listAccessKeysReply)
+	})
+
+	mux.HandleFunc(oldAccessKeyPath, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "DELETE "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return &requests
+}
+
+func TestAims_OffboardUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := offboardHandlers(t, false)
+
+	report, err := client.OffboardUser(testEmail, OffboardUserOptions{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, testUserId, report.User.ID)
+		assert.Len(t, report.Roles, 1)
+		assert.Len(t, report.AccessKeys, 1)
+		assert.Equal(t, []string{testRoleId}, report.RevokedRoleIds)
+		assert.Equal(t, []string{testAccessKeyId}, report.DeletedAccessKeys)
+		assert.True(t, report.Deleted)
+		assert.False(t, report.Deactivated)
+		assert.True(t, report.Verified)
+		assert.Empty(t, report.Errors)
+		assert.Equal(t, []string{
+			"DELETE " + revokeUserRolePath,
+			"DELETE " + oldAccessKeyPath,
+			"DELETE " + getUserDetailsPath,
+		}, *requests)
+	}
+}
+
+func TestAims_OffboardUserDeactivate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := offboardHandlers(t, false)
+
+	report, err := client.OffboardUser(testUserId, OffboardUserOptions{Deactivate: true})
+
+	if assert.NoError(t, err) {
+		assert.True(t, report.Deactivated)
+		assert.False(t, report.Deleted)
+		assert.True(t, report.Verified)
+		assert.Equal(t, "POST "+getUserDetailsPath+" active=false", (*requests)[2])
+	}
+}
+
+func TestAims_OffboardUserPartialFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	offboardHandlers(t, true)
+
+	report, err := client.OffboardUser(testUserId, OffboardUserOptions{Deactivate: true})
+
+	assert.Error(t, err)
+	assert.Empty(t, report.RevokedRoleIds)
+	assert.Equal(t, []string{testAccessKeyId}, report.DeletedAccessKeys)
+	assert.True(t, report.Deactivated)
+	assert.False(t, report.Verified)
+	if assert.Len(t, report.Errors, 2) {
+		assert.Contains(t, report.Errors[0], `error revoking role "Read Only"`)
+		assert.Equal(t, "user still holds 1 roles", report.Errors[1])
+	}
+}
+
+func TestAims_OffboardUserUnverified(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %q, "active": true}`, testUserId)
+	})
+	mux.HandleFunc(getAssignedRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"roles": []}`)
+	})
+	mux.HandleFunc(accessKeysPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_keys": []}`)
+	})
+
+	report, err := client.OffboardUser(testUserId, OffboardUserOptions{})
+
+	assert.EqualError(t, err, fmt.Sprintf("offboarding user %s did not complete: user still exists after deletion", testUserId))
+	assert.True(t, report.Deleted)
+	assert.False(t, report.Verified)
+}
+
+func TestAims_OffboardUserNotFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getUserDetailsByUsernamePath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q, "account_id": %q}`, testUserId, testRelatedAccountId)
+	})
+
+	_, err := client.OffboardUser(testUserId, OffboardUserOptions{})
+
+	assert.EqualError(t, err, fmt.Sprintf("user %s does not exist", testUserId))
+
+	_, err = client.OffboardUser("", OffboardUserOptions{})
+
+	assert.EqualError(t, err, errEmptyUser)
+}
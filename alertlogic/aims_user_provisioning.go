@@ -0,0 +1,286 @@
+package alertlogic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// defaultProvisionConcurrency is the number of users ProvisionUsers provisions at once when no
+// concurrency is given.
+const defaultProvisionConcurrency = 4
+
+// provisionRoleSeparators separate role names in the `roles` column of a provisioning CSV.
+const provisionRoleSeparators = ";|"
+
+// ProvisionUserRow is a single user to provision. `Roles` are role names.
+type ProvisionUserRow struct {
+	Name              string   `json:"name"`
+	Email             string   `json:"email"`
+	Phone             string   `json:"phone,omitempty"`
+	Roles             []string `json:"roles,omitempty"`
+	NotificationsOnly bool     `json:"notifications_only,omitempty"`
+	WebhookUrl        string   `json:"webhook_url,omitempty"`
+}
+
+// ProvisionStatus is the outcome of provisioning a single user.
+type ProvisionStatus string
+
+const (
+	ProvisionCreated  ProvisionStatus = "created"
+	ProvisionExisting ProvisionStatus = "existing"
+	ProvisionFailed   ProvisionStatus = "failed"
+)
+
+// ProvisionUserResult is the outcome of provisioning a single row. `Row` is the zero-based index
+// of the row in the input and `RolesGranted` holds the names of roles granted by this run.
+type ProvisionUserResult struct {
+	Row          int              `json:"row"`
+	Input        ProvisionUserRow `json:"input"`
+	Status       ProvisionStatus  `json:"status"`
+	UserID       string           `json:"user_id,omitempty"`
+	RolesGranted []string         `json:"roles_granted,omitempty"`
+	Error        string           `json:"error,omitempty"`
+}
+
+// ProvisionReport holds the result of every row given to ProvisionUsers, in input order.
+type ProvisionReport struct {
+	Results []ProvisionUserResult `json:"results"`
+}
+
+// FailedRows returns the input rows that failed, so that they can be provisioned again.
+func (r ProvisionReport) FailedRows() []ProvisionUserRow {
+	rows := []ProvisionUserRow{}
+	for _, result := range r.Results {
+		if result.Status == ProvisionFailed {
+			rows = append(rows, result.Input)
+		}
+	}
+
+	return rows
+}
+
+// ParseProvisionUsersCSV parses users to provision from CSV. The first record is a header naming
+// the columns: `name` and `email` are required, and `phone`, `roles`, `notifications_only` and
+// `webhook_url` are optional. Multiple role names in the `roles` column are separated by `;` or
+// `|`.
+func ParseProvisionUsersCSV(r io.Reader) ([]ProvisionUserRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading provisioning CSV")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("provisioning CSV has no header")
+	}
+
+	columns := map[string]int{}
+	for i, column := range records[0] {
+		column = strings.ToLower(strings.TrimSpace(column))
+		switch column {
+		case "name", "email", "phone", "roles", "notifications_only", "webhook_url":
+			columns[column] = i
+		default:
+			return nil, errors.Errorf("unknown provisioning CSV column %q", column)
+		}
+	}
+	for _, required := range []string{"name", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, errors.Errorf("provisioning CSV is missing the %q column", required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	rows := []ProvisionUserRow{}
+	for n, record := range records[1:] {
+		row := ProvisionUserRow{
+			Name:       field(record, "name"),
+			Email:      field(record, "email"),
+			Phone:      field(record, "phone"),
+			WebhookUrl: field(record, "webhook_url"),
+		}
+
+		for _, role := range strings.FieldsFunc(field(record, "roles"), func(r rune) bool {
+			return strings.ContainsRune(provisionRoleSeparators, r)
+		}) {
+			if role = strings.TrimSpace(role); role != "" {
+				row.Roles = append(row.Roles, role)
+			}
+		}
+
+		if value := field(record, "notifications_only"); value != "" {
+			row.NotificationsOnly, err = strconv.ParseBool(value)
+			if err != nil {
+				return nil, errors.Errorf("invalid notifications_only value %q on line %d", value, n+2)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ParseProvisionUsersJSON parses users to provision from a JSON array of ProvisionUserRow.
+func ParseProvisionUsersJSON(r io.Reader) ([]ProvisionUserRow, error) {
+	var rows []ProvisionUserRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, errors.Wrap(err, "error reading provisioning JSON")
+	}
+
+	return rows, nil
+}
+
+// ProvisionUsers creates a user for every row and grants them the row's roles, resolving role
+// names with ListRoles. At most `maxConcurrency` rows are provisioned at once; a value less than
+// one uses a default of 4.
+// Rows whose email address already belongs to a user in the account are not created again, but
+// are granted any of their roles they do not already hold, so the same input can be provisioned
+// again after a partial failure. The returned error is non-nil if any row failed, and the report
+// holds the result of every row.
+func (api *API) ProvisionUsers(rows []ProvisionUserRow, maxConcurrency int) (ProvisionReport, error) {
+	if maxConcurrency < 1 {
+		maxConcurrency = defaultProvisionConcurrency
+	}
+
+	roles, err := api.ListRoles()
+	if err != nil {
+		return ProvisionReport{}, err
+	}
+	roleIds := map[string]string{}
+	for _, role := range roles.Roles {
+		roleIds[role.Name] = role.ID
+	}
+
+	users, err := api.ListUsers(false, false, true, "")
+	if err != nil {
+		return ProvisionReport{}, err
+	}
+	existing := map[string]User{}
+	for _, user := range users.Users {
+		existing[strings.ToLower(user.Email)] = user
+	}
+
+	report := ProvisionReport{Results: make([]ProvisionUserResult, len(rows))}
+	seen := map[string]bool{}
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+
+	for i, row := range rows {
+		report.Results[i] = ProvisionUserResult{Row: i, Input: row}
+		result := &report.Results[i]
+
+		email := strings.ToLower(row.Email)
+		if err := validateProvisionRow(row, roleIds); err != nil {
+			result.fail(err)
+			continue
+		}
+		if seen[email] {
+			result.fail(errors.Errorf("email %s appears more than once", row.Email))
+			continue
+		}
+		seen[email] = true
+
+		user, exists := existing[email]
+
+		wg.Add(1)
+		go func(row ProvisionUserRow) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			api.provisionUser(row, user, exists, roleIds, result)
+		}(row)
+	}
+
+	wg.Wait()
+
+	failed := 0
+	for _, result := range report.Results {
+		if result.Status == ProvisionFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return report, errors.Errorf("%d of %d users failed to provision", failed, len(rows))
+	}
+
+	return report, nil
+}
+
+// provisionUser creates a single user, unless they already exist, and grants them any roles
+// they do not hold, recording the outcome in `result`.
+func (api *API) provisionUser(row ProvisionUserRow, user User, exists bool, roleIds map[string]string, result *ProvisionUserResult) {
+	if exists {
+		result.Status = ProvisionExisting
+	} else {
+		var err error
+		user, err = api.CreateUser(CreateUserRequest{
+			Name:              row.Name,
+			Email:             row.Email,
+			Phone:             row.Phone,
+			Active:            true,
+			WebhookUrl:        row.WebhookUrl,
+			NotificationsOnly: row.NotificationsOnly,
+		}, false)
+		if err != nil {
+			result.fail(errors.Wrap(err, "error creating user"))
+			return
+		}
+		result.Status = ProvisionCreated
+	}
+	result.UserID = user.ID
+
+	held := map[string]bool{}
+	if user.RoleIds != nil {
+		for _, roleId := range *user.RoleIds {
+			held[roleId] = true
+		}
+	}
+
+	for _, role := range row.Roles {
+		if held[roleIds[role]] {
+			continue
+		}
+		if _, err := api.GrantUserRole(user.ID, roleIds[role]); err != nil {
+			result.fail(errors.Wrapf(err, "error granting role %q", role))
+			return
+		}
+		held[roleIds[role]] = true
+		result.RolesGranted = append(result.RolesGranted, role)
+	}
+}
+
+// fail marks the result as failed with `err`.
+func (r *ProvisionUserResult) fail(err error) {
+	r.Status = ProvisionFailed
+	r.Error = err.Error()
+}
+
+// validateProvisionRow checks that a row has the required fields and only names known roles.
+func validateProvisionRow(row ProvisionUserRow, roleIds map[string]string) error {
+	if row.Name == "" || row.Email == "" {
+		return errors.New("name and email must not be empty")
+	}
+
+	for _, role := range row.Roles {
+		if _, ok := roleIds[role]; !ok {
+			return errors.Errorf("role %q does not exist", role)
+		}
+	}
+
+	return nil
+}
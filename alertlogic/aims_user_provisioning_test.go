@@ -0,0 +1,163 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const provisionCSV = `name,email,phone,roles,notifications_only,webhook_url
+Bob Loblaw,bob@bobloblawlaw.com,123-555-0123,Read Only;Power User,false,
+Lucille Bluth, lucille@bluth.com,,Read Only,true,https://hooks.bluth.com/alerts
+`
+
+func TestAims_ParseProvisionUsersCSV(t *testing.T) {
+	rows, err := ParseProvisionUsersCSV(strings.NewReader(provisionCSV))
+
+	want := []ProvisionUserRow{
+		{Name: testUserFullName, Email: testEmail, Phone: "123-555-0123", Roles: []string{"Read Only", "Power User"}},
+		{Name: "Lucille Bluth", Email: "lucille@bluth.com", Roles: []string{"Read Only"}, NotificationsOnly: true, WebhookUrl: "https://hooks.bluth.com/alerts"},
+	}
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, rows)
+	}
+}
+
+func TestAims_ParseProvisionUsersCSVErrors(t *testing.T) {
+	var csvTests = []struct {
+		csv string
+		err string
+	}{
+		{"", "provisioning CSV has no header"},
+		{"name,email,manager\n", `unknown provisioning CSV column "manager"`},
+		{"name,phone\n", `provisioning CSV is missing the "email" column`},
+		{"name,email,notifications_only\nBob,bob@bobloblawlaw.com,sometimes\n", `invalid notifications_only value "sometimes" on line 2`},
+	}
+
+	for _, tt := range csvTests {
+		_, err := ParseProvisionUsersCSV(strings.NewReader(tt.csv))
+
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestAims_ParseProvisionUsersJSON(t *testing.T) {
+	rows, err := ParseProvisionUsersJSON(strings.NewReader(`[{"name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "roles": ["Read Only"]}]`))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []ProvisionUserRow{{Name: testUserFullName, Email: testEmail, Roles: []string{"Read Only"}}}, rows)
+	}
+
+	_, err = ParseProvisionUsersJSON(strings.NewReader(`{`))
+	assert.Error(t, err)
+}
+
+// provisionHandlers serves roles and users for provisioning tests and records created users and
+// role grants. Creating a user with an email in `failEmails` fails.
+func provisionHandlers(t *testing.T, failEmails ...string) (*[]string, *[]string) {
+	var mu sync.Mutex
+	created := []string{}
+	granted := []string{}
+
+	mux.HandleFunc(listRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"roles": [{"id": "R1", "name": "Read Only"}, {"id": "R2", "name": "Power User"}]}`)
+	})
+
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{"users": [{"id": "EXISTING", "email": "Lucille@bluth.com", "role_ids": ["R1"]}]}`)
+			return
+		}
+
+		var request CreateUserRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		for _, email := range failEmails {
+			if request.Email == email {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_email"}`)
+				return
+			}
+		}
+
+		mu.Lock()
+		created = append(created, request.Email)
+		mu.Unlock()
+		assert.True(t, request.Active)
+		fmt.Fprintf(w, `{"id": "NEW-%s", "email": %q}`, request.Email, request.Email)
+	})
+
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/users/", aimsServicePath, testAccountId), func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		mu.Lock()
+		granted = append(granted, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return &created, &granted
+}
+
+func TestAims_ProvisionUsers(t *testing.T) {
+	setup()
+	defer teardown()
+
+	created, granted := provisionHandlers(t)
+	rows, _ := ParseProvisionUsersCSV(strings.NewReader(provisionCSV))
+
+	report, err := client.ProvisionUsers(rows, 2)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []ProvisionUserResult{
+			{Row: 0, Input: rows[0], Status: ProvisionCreated, UserID: "NEW-" + testEmail, RolesGranted: []string{"Read Only", "Power User"}},
+			{Row: 1, Input: rows[1], Status: ProvisionExisting, UserID: "EXISTING"},
+		}, report.Results)
+		assert.Equal(t, []string{testEmail}, *created)
+		assert.ElementsMatch(t, []string{
+			fmt.Sprintf("/%s/%s/users/NEW-%s/roles/R1", aimsServicePath, testAccountId, testEmail),
+			fmt.Sprintf("/%s/%s/users/NEW-%s/roles/R2", aimsServicePath, testAccountId, testEmail),
+		}, *granted)
+		assert.Empty(t, report.FailedRows())
+	}
+}
+
+func TestAims_ProvisionUsersPartialFailure(t *testing.T) {
+	setup()
+	defer teardown()
+
+	created, _ := provisionHandlers(t, "gob@bluth.com")
+	rows := []ProvisionUserRow{
+		{Name: "Gob Bluth", Email: "gob@bluth.com"},
+		{Name: "Buster Bluth", Email: "buster@bluth.com", Roles: []string{"Juice Box"}},
+		{Name: "Michael Bluth", Email: "michael@bluth.com"},
+		{Name: "Michael Again", Email: "MICHAEL@bluth.com"},
+		{Email: "nameless@bluth.com"},
+	}
+
+	report, err := client.ProvisionUsers(rows, 0)
+
+	assert.EqualError(t, err, "4 of 5 users failed to provision")
+	assert.Equal(t, []string{"michael@bluth.com"}, *created)
+	assert.Equal(t, ProvisionFailed, report.Results[0].Status)
+	assert.Contains(t, report.Results[0].Error, "error creating user")
+	assert.Equal(t, `role "Juice Box" does not exist`, report.Results[1].Error)
+	assert.Equal(t, ProvisionCreated, report.Results[2].Status)
+	assert.Equal(t, "email MICHAEL@bluth.com appears more than once", report.Results[3].Error)
+	assert.Equal(t, "name and email must not be empty", report.Results[4].Error)
+	assert.Equal(t, []ProvisionUserRow{rows[0], rows[1], rows[3], rows[4]}, report.FailedRows())
+}
+
+func TestAims_ProvisionUsersRolesError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.ProvisionUsers([]ProvisionUserRow{{Name: testUserFullName, Email: testEmail}}, 1)
+
+	assert.Error(t, err)
+}
@@ -36,22 +36,23 @@ type Account struct {
 
 // User is the user level information.
 type User struct {
-	ID             string          `json:"id,omitempty"`
-	AccountID      string          `json:"account_id,omitempty"`
-	Name           string          `json:"name,omitempty"`
-	Username       string          `json:"username,omitempty"`
-	Email          string          `json:"email,omitempty"`
-	Active         bool            `json:"active,omitempty"`
-	Locked         bool            `json:"locked,omitempty"`
-	Version        int64           `json:"version,omitempty"`
-	MfaEnabled     *bool           `json:"mfa_enabled,omitempty"`
-	MobilePhone    *string         `json:"mobile_phone,omitempty"`
-	LinkedUsers    []LinkedUser    `json:"linked_users,omitempty"`
-	UserCredential *UserCredential `json:"user_credential,omitempty"`
-	RoleIds        *[]string       `json:"role_ids,omitempty"`
-	AccessKeys     *[]AccessKey    `json:"access_keys,omitempty"`
-	Created        ModifiedCreated `json:"created,omitempty"`
-	Modified       ModifiedCreated `json:"modified,omitempty"`
+	ID                string          `json:"id,omitempty"`
+	AccountID         string          `json:"account_id,omitempty"`
+	Name              string          `json:"name,omitempty"`
+	Username          string          `json:"username,omitempty"`
+	Email             string          `json:"email,omitempty"`
+	Active            bool            `json:"active,omitempty"`
+	Locked            bool            `json:"locked,omitempty"`
+	NotificationsOnly bool            `json:"notifications_only,omitempty"`
+	Version           int64           `json:"version,omitempty"`
+	MfaEnabled        *bool           `json:"mfa_enabled,omitempty"`
+	MobilePhone       *string         `json:"mobile_phone,omitempty"`
+	LinkedUsers       []LinkedUser    `json:"linked_users,omitempty"`
+	UserCredential    *UserCredential `json:"user_credential,omitempty"`
+	RoleIds           *[]string       `json:"role_ids,omitempty"`
+	AccessKeys        *[]AccessKey    `json:"access_keys,omitempty"`
+	Created           ModifiedCreated `json:"created,omitempty"`
+	Modified          ModifiedCreated `json:"modified,omitempty"`
 }
 
 // UserCredential is a user's credential information.
@@ -63,12 +64,15 @@ type UserCredential struct {
 	Modified        ModifiedCreated `json:"modified,omitempty"`
 }
 
+// AccessKey is an access key belonging to a user.
+// `SecretKey` is only returned by the API when the access key is created.
 type AccessKey struct {
 	Label       string          `json:"label,omitempty"`
 	LastLogin   int             `json:"last_login,omitempty"`
 	Created     ModifiedCreated `json:"created,omitempty"`
 	Modified    ModifiedCreated `json:"modified,omitempty"`
 	AccessKeyID string          `json:"access_key_id,omitempty"`
+	SecretKey   string          `json:"secret_key,omitempty"`
 }
 
 // LinkedUser are any users linked to the current user.
@@ -90,8 +94,18 @@ type CreateUserRequest struct {
 	NotificationsOnly bool   `json:"notifications_only,omitempty"`
 }
 
-// UpdateUserRequest holds the user update request data.
-type UpdateUserRequest = CreateUserRequest
+// UpdateUserRequest holds the user update request data. Only fields that are set are sent, so a
+// request may update a single attribute without affecting the others.
+type UpdateUserRequest struct {
+	Name              *string `json:"name,omitempty"`
+	Email             *string `json:"email,omitempty"`
+	Password          *string `json:"password,omitempty"`
+	Active            *bool   `json:"active,omitempty"`
+	MobilePhone       *string `json:"mobile_phone,omitempty"`
+	Phone             *string `json:"phone,omitempty"`
+	WebhookUrl        *string `json:"webhook_url,omitempty"`
+	NotificationsOnly *bool   `json:"notifications_only,omitempty"`
+}
 
 // ListUsersByEmailResponse holds the response from list users by email.
 type UserList struct {
@@ -205,16 +219,17 @@ func (api *API) ListUsers(includeAccessKeys bool, includeUserCredentials bool, i
 	return api.getUsers(fmt.Sprintf("%s/%s/users", aimsServicePath, api.AccountID), includeAccessKeys, includeUserCredentials, includeRoleIds, roleId)
 }
 
-// UpdateUserDetails updates a user.
+// UpdateUserDetails updates a user. Only the fields set in `user` are changed.
 // If true, `oneTimePassword` will set the user's password as a one-time password and require them
 // to supply a new password upon first login.
-// If `Password` is not supplied in the `UpdateUserRequest`, the user will be emailed a link to
-// set their password.
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-UpdateUser
 func (api *API) UpdateUserDetails(userId string, user UpdateUserRequest, oneTimePassword bool) (User, error) {
-	if oneTimePassword && user.Password == "" {
-		return User{}, errors.New("oneTimePassword must be accompanied by CreateUserRequest.Password")
+	if user == (UpdateUserRequest{}) {
+		return User{}, errors.New(errEmptyUpdateUserRequest)
+	}
+	if oneTimePassword && (user.Password == nil || *user.Password == "") {
+		return User{}, errors.New("oneTimePassword must be accompanied by UpdateUserRequest.Password")
 	}
 
 	var params map[string]string
@@ -237,6 +252,20 @@ func (api *API) UpdateUserDetails(userId string, user UpdateUserRequest, oneTime
 	return r, nil
 }
 
+// ActivateUser activates a user.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-UpdateUser
+func (api *API) ActivateUser(userId string) (User, error) {
+	return api.UpdateUserDetails(userId, UpdateUserRequest{Active: Bool(true)}, false)
+}
+
+// DeactivateUser deactivates a user without deleting them. A deactivated user cannot log in.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/aims/#api-AIMS_User_Resources-UpdateUser
+func (api *API) DeactivateUser(userId string) (User, error) {
+	return api.UpdateUserDetails(userId, UpdateUserRequest{Active: Bool(false)}, false)
+}
+
 // GetUserDetailsByUsername retrieves a user's details by their username.
 // You can include access keys, credentials, or role IDs with includeAccessKeys,
 // includeUserCredentials, and includeRoleIds respectively.
@@ -2,6 +2,7 @@ package alertlogic
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"testing"
 
@@ -710,13 +711,13 @@ func TestAims_UpdateUser(t *testing.T) {
 		Modified:    ModifiedCreated{At: 1430185015, By: "System"},
 	}
 
-	user, err := client.UpdateUserDetails(testUserId, UpdateUserRequest{Email: "new@email.com"}, false)
+	user, err := client.UpdateUserDetails(testUserId, UpdateUserRequest{Email: String("new@email.com")}, false)
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, user, want)
 	}
 
-	user, err = client.UpdateUserDetails(testUserId, UpdateUserRequest{Email: "new@email.com", Password: "password"}, true)
+	user, err = client.UpdateUserDetails(testUserId, UpdateUserRequest{Email: String("new@email.com"), Password: String("password")}, true)
 
 	if assert.NoError(t, err) {
 		assert.Equal(t, user, want)
@@ -724,11 +725,72 @@ func TestAims_UpdateUser(t *testing.T) {
 }
 
 func TestAims_UpdateUserOneTimePasswordMissingPassword(t *testing.T) {
-	_, err := client.UpdateUserDetails(testUserId, UpdateUserRequest{Email: "new@email.com"}, true)
+	_, err := client.UpdateUserDetails(testUserId, UpdateUserRequest{Email: String("new@email.com")}, true)
 
 	assert.Error(t, err, "oneTimePassword must be accompanied by UpdateUserRequest.Password")
 }
 
+func TestAims_UpdateUserEmptyRequest(t *testing.T) {
+	_, err := client.UpdateUserDetails(testUserId, UpdateUserRequest{}, false)
+
+	assert.EqualError(t, err, errEmptyUpdateUserRequest)
+}
+
+func TestAims_UpdateUserPartial(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(updateUserPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"notifications_only": false, "mobile_phone": ""}`, string(body))
+
+		fmt.Fprintf(w, `{"id": %q}`, testUserId)
+	})
+
+	_, err := client.UpdateUserDetails(testUserId, UpdateUserRequest{NotificationsOnly: Bool(false), MobilePhone: String("")}, false)
+
+	assert.NoError(t, err)
+}
+
+func TestAims_ActivateUser(t *testing.T) {
+	var activateTests = []struct {
+		activate bool
+		body     string
+	}{
+		{true, `{"active": true}`},
+		{false, `{"active": false}`},
+	}
+
+	for _, tt := range activateTests {
+		setup()
+
+		mux.HandleFunc(updateUserPath, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.JSONEq(t, tt.body, string(body))
+
+			fmt.Fprintf(w, `{"id": %q, "active": %t}`, testUserId, tt.activate)
+		})
+
+		var user User
+		var err error
+		if tt.activate {
+			user, err = client.ActivateUser(testUserId)
+		} else {
+			user, err = client.DeactivateUser(testUserId)
+		}
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.activate, user.Active)
+		}
+
+		teardown()
+	}
+}
+
 func TestAims_GetUserDetailsByUsername(t *testing.T) {
 	setup()
 	defer teardown()
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -24,6 +25,9 @@ const (
 	deploymentServicePath = "deployments/v1"
 )
 
+// timeNow returns the current time and is replaced in tests.
+var timeNow = time.Now
+
 // API holds the configuration for the current API client.
 type API struct {
 	Username   string
@@ -42,6 +46,30 @@ type ModifiedCreated struct {
 	By string `json:"by,omitempty"`
 }
 
+// Time returns `At` as a time.Time. The zero time is returned when `At` is not set.
+func (m ModifiedCreated) Time() time.Time {
+	if m.At == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(m.At), 0)
+}
+
+// Bool returns a pointer to `v`, for setting optional request fields.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// String returns a pointer to `v`, for setting optional request fields.
+func String(v string) *string {
+	return &v
+}
+
+// Int64 returns a pointer to `v`, for setting optional request fields.
+func Int64(v int64) *int64 {
+	return &v
+}
+
 // newClient creates a new API client.
 func newClient(accountId string) (*API, error) {
 	if accountId == "" {
@@ -176,24 +204,24 @@ func (api *API) makeRequest(
 	switch {
 	case resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices:
 	case resp.StatusCode == http.StatusUnauthorized:
-		return nil, resp.StatusCode, errors.Errorf("HTTP status %d: invalid credentials", resp.StatusCode)
+		return nil, resp.StatusCode, newAPIError(resp.StatusCode, "HTTP status %d: invalid credentials", resp.StatusCode)
 	case resp.StatusCode == http.StatusForbidden:
-		return nil, resp.StatusCode, errors.Errorf("HTTP status %d: insufficient permissions", resp.StatusCode)
+		return nil, resp.StatusCode, newAPIError(resp.StatusCode, "HTTP status %d: insufficient permissions", resp.StatusCode)
 	case resp.StatusCode == http.StatusServiceUnavailable,
 		resp.StatusCode == http.StatusBadGateway,
 		resp.StatusCode == http.StatusGatewayTimeout,
 		resp.StatusCode == 522,
 		resp.StatusCode == 523,
 		resp.StatusCode == 524:
-		return nil, resp.StatusCode, errors.Errorf("HTTP status %d: service failure", resp.StatusCode)
+		return nil, resp.StatusCode, newAPIError(resp.StatusCode, "HTTP status %d: service failure", resp.StatusCode)
 	case resp.StatusCode == 400:
-		return nil, resp.StatusCode, errors.Errorf("%s", respBody)
+		return nil, resp.StatusCode, newAPIError(resp.StatusCode, "%s", respBody)
 	default:
 		var s string
 		if respBody != nil {
 			s = string(respBody)
 		}
-		return nil, resp.StatusCode, errors.Errorf("HTTP status %d: content %q", resp.StatusCode, s)
+		return nil, resp.StatusCode, newAPIError(resp.StatusCode, "HTTP status %d: content %q", resp.StatusCode, s)
 	}
 
 	return respBody, resp.StatusCode, nil
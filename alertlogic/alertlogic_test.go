@@ -1,6 +1,7 @@
 package alertlogic
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -90,3 +91,11 @@ func TestAlertLogic_NewMissingAccountId(t *testing.T) {
 	assert.Error(t, errApiToken)
 	assert.Equal(t, errApiToken.Error(), errEmptyAccountId)
 }
+
+// writeJSON writes `v` as a JSON response.
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	w.Header().Set("content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultAssetBatchSize is the default maximum number of operations sent in one request by
+	// WriteAssetBatch.
+	DefaultAssetBatchSize = 100
+	// DefaultAssetBatchBytes is the default maximum size in bytes of the body of one request sent
+	// by WriteAssetBatch.
+	DefaultAssetBatchBytes = 1 << 20
+)
+
+// AssetBatchOptions control how WriteAssetBatch splits operations into requests. Zero values use
+// DefaultAssetBatchSize and DefaultAssetBatchBytes.
+type AssetBatchOptions struct {
+	ChunkSize  int
+	ChunkBytes int
+}
+
+// AssetBatchResult is the outcome of a single operation in a batch. `StatusCode` is the status
+// of the request that carried the operation, or zero if it was never sent.
+type AssetBatchResult struct {
+	Request    ExternalDNSAssetRequest `json:"request"`
+	StatusCode int                     `json:"status_code"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// Failed reports whether the operation failed.
+func (r AssetBatchResult) Failed() bool {
+	return r.Error != ""
+}
+
+// AssetBatchReport holds the result of every operation in a batch, in the order they were given,
+// and the number of requests made.
+type AssetBatchReport struct {
+	Results  []AssetBatchResult `json:"results"`
+	Requests int                `json:"requests"`
+}
+
+// Failed returns the results of the operations that failed.
+func (r AssetBatchReport) Failed() []AssetBatchResult {
+	failed := []AssetBatchResult{}
+	for _, result := range r.Results {
+		if result.Failed() {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// NewDeclareExternalDNSNameRequest returns an operation that declares an asset of the type
+// `external-dns-name`, for use with WriteAssetBatch. `dnsName` is normalized with
+// NormalizeDNSName, and a *DNSNameError is returned if it is not valid.
+func NewDeclareExternalDNSNameRequest(dnsName string) (ExternalDNSAssetRequest, error) {
+	dnsName, err := NormalizeDNSName(dnsName)
+	if err != nil {
+		return ExternalDNSAssetRequest{}, err
+	}
+
+	return ExternalDNSAssetRequest{
+		Operation: "declare_asset",
+		Type:      AssetTypeExternalDNSName,
+		Key:       externalDNSNameKey(dnsName),
+		Properties: map[string]string{
+			"dns_name": dnsName,
+			"name":     dnsName,
+			"state":    "new",
+		},
+	}, nil
+}
+
+// NewRemoveExternalDNSNameRequest returns an operation that removes an asset of the type
+// `external-dns-name`, for use with WriteAssetBatch. `dnsName` is normalized with
+// NormalizeDNSName, and a *DNSNameError is returned if it is not valid. To remove an asset by its
+// exact key, set `Key` on an ExternalDNSAssetRequest with the `remove_asset` operation instead.
+func NewRemoveExternalDNSNameRequest(dnsName string) (ExternalDNSAssetRequest, error) {
+	dnsName, err := NormalizeDNSName(dnsName)
+	if err != nil {
+		return ExternalDNSAssetRequest{}, err
+	}
+
+	return ExternalDNSAssetRequest{
+		Operation: "remove_asset",
+		Type:      AssetTypeExternalDNSName,
+		Key:       externalDNSNameKey(dnsName),
+	}, nil
+}
+
+// WriteAssetBatch sends many declare or remove operations to a deployment, several operations per
+// request. Operations without a scope are given the deployment's platform type. Operations that
+// declare external DNS names fail without being sent if the name is not valid or the key is not
+// the normalized key of the name.
+// Operations are split into chunks of at most `options.ChunkSize` operations and
+// `options.ChunkBytes` bytes. If the API rejects a chunk as too large, it is split in half and
+// each half is sent again. Any other failure fails every operation in the chunk, and does not
+// stop the remaining chunks.
+// The returned error is non-nil if any operation failed, and the report holds the result of every
+// operation.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-DeclareAsset
+func (api *API) WriteAssetBatch(deploymentId string, requests []ExternalDNSAssetRequest, options AssetBatchOptions) (AssetBatchReport, error) {
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultAssetBatchSize
+	}
+	if options.ChunkBytes <= 0 {
+		options.ChunkBytes = DefaultAssetBatchBytes
+	}
+
+	report := AssetBatchReport{Results: make([]AssetBatchResult, len(requests))}
+
+	scope := ""
+	for _, request := range requests {
+		if request.Scope == "" {
+			var err error
+			if scope, err = api.deploymentScope(deploymentId); err != nil {
+				return report, err
+			}
+			break
+		}
+	}
+
+	pending := []int{}
+	sizes := map[int]int{}
+	for i, request := range requests {
+		if request.Scope == "" {
+			request.Scope = scope
+		}
+		report.Results[i].Request = request
+
+		if request.Operation == "" || request.Type == "" || request.Key == "" {
+			report.Results[i].Error = "asset operation, type and key must not be empty"
+			continue
+		}
+		if err := validateExternalDNSNameRequest(request); err != nil {
+			report.Results[i].Error = err.Error()
+			continue
+		}
+
+		data, err := json.Marshal(request)
+		if err != nil {
+			report.Results[i].Error = err.Error()
+			continue
+		}
+		sizes[i] = len(data)
+		pending = append(pending, i)
+	}
+
+	for _, chunk := range assetBatchChunks(pending, sizes, options) {
+		api.writeAssetChunk(deploymentId, chunk, &report)
+	}
+
+	failed := len(report.Failed())
+	if failed > 0 {
+		return report, errors.Errorf("%d of %d asset operations failed", failed, len(requests))
+	}
+
+	return report, nil
+}
+
+// validateExternalDNSNameRequest checks that an operation declaring an external DNS name has a
+// valid `dns_name` property and the key that NormalizeDNSName gives for it, so that a batch cannot
+// declare an asset that duplicates one under another spelling of its name.
+func validateExternalDNSNameRequest(request ExternalDNSAssetRequest) error {
+	if request.Operation != "declare_asset" || request.Type != AssetTypeExternalDNSName {
+		return nil
+	}
+
+	dnsName, err := NormalizeDNSName(request.Properties["dns_name"])
+	if err != nil {
+		return err
+	}
+	if key := externalDNSNameKey(dnsName); request.Key != key {
+		return errors.Errorf("key %s does not match the normalized key %s", request.Key, key)
+	}
+
+	return nil
+}
+
+// writeAssetChunk sends the operations at `indexes` in one request, recording their results in
+// `report`. A chunk rejected as too large is split in half.
+func (api *API) writeAssetChunk(deploymentId string, indexes []int, report *AssetBatchReport) {
+	requests := make([]ExternalDNSAssetRequest, len(indexes))
+	for i, index := range indexes {
+		requests[i] = report.Results[index].Request
+	}
+
+	_, statusCode, err := api.makeRequest("PUT", fmt.Sprintf("%s/%s/deployments/%s/assets", assetsWriteServicePath, api.AccountID, deploymentId), nil, nil, requests)
+	report.Requests++
+
+	if statusCode == http.StatusRequestEntityTooLarge && len(indexes) > 1 {
+		half := len(indexes) / 2
+		api.writeAssetChunk(deploymentId, indexes[:half], report)
+		api.writeAssetChunk(deploymentId, indexes[half:], report)
+		return
+	}
+
+	for _, index := range indexes {
+		report.Results[index].StatusCode = statusCode
+		if err != nil {
+			report.Results[index].Error = errors.Wrap(err, errMakeRequestError).Error()
+		}
+	}
+}
+
+// assetBatchChunks splits the operations at `indexes` into chunks within the limits of
+// `options`. `sizes` holds the encoded size of each operation. An operation larger than
+// `options.ChunkBytes` is sent in a chunk of its own.
+func assetBatchChunks(indexes []int, sizes map[int]int, options AssetBatchOptions) [][]int {
+	chunks := [][]int{}
+
+	var chunk []int
+	chunkBytes := 0
+	for _, index := range indexes {
+		// Each operation adds its own size and a separating comma to the enclosing brackets.
+		size := sizes[index] + 1
+		if len(chunk) > 0 && (len(chunk) == options.ChunkSize || chunkBytes+size > options.ChunkBytes) {
+			chunks = append(chunks, chunk)
+			chunk, chunkBytes = nil, 0
+		}
+		if len(chunk) == 0 {
+			chunkBytes = 1
+		}
+		chunk = append(chunk, index)
+		chunkBytes += size
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
@@ -0,0 +1,204 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchHandler records the keys in each batch request and rejects requests with more than
+// `maxOperations` operations as too large, or any request with a key in `reject`.
+func batchHandler(t *testing.T, maxOperations int, reject string) *[][]string {
+	batches := [][]string{}
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		var requests []ExternalDNSAssetRequest
+		assert.NoError(t, json.Unmarshal(body, &requests))
+
+		keys := make([]string, len(requests))
+		for i, request := range requests {
+			keys[i] = request.Key
+			if request.Key == reject {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid asset"}`)
+				return
+			}
+		}
+		batches = append(batches, keys)
+
+		if len(requests) > maxOperations {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return &batches
+}
+
+// declareDNSName returns an operation that declares `dnsName`, failing the test if it is invalid.
+func declareDNSName(t *testing.T, dnsName string) ExternalDNSAssetRequest {
+	request, err := NewDeclareExternalDNSNameRequest(dnsName)
+	assert.NoError(t, err)
+
+	return request
+}
+
+func TestAssetsBatch_WriteAssetBatch(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deploymentRequests := 0
+	mux.HandleFunc(getDeploymentPath, func(w http.ResponseWriter, r *http.Request) {
+		deploymentRequests++
+		fmt.Fprint(w, `{"id": "50668317-feb8-49d1-b401-7219bfa22417", "platform": {"type": "datacenter"}}`)
+	})
+	batches := batchHandler(t, 10, "")
+
+	remove, err := NewRemoveExternalDNSNameRequest("C.Example.com.")
+	assert.NoError(t, err)
+
+	requests := []ExternalDNSAssetRequest{
+		declareDNSName(t, "a.example.com"),
+		declareDNSName(t, "B.example.com"),
+		remove,
+		declareDNSName(t, "d.example.com"),
+		declareDNSName(t, "e.example.com"),
+	}
+	requests[4].Scope = "aws"
+
+	report, err := client.WriteAssetBatch(testDeploymentId, requests, AssetBatchOptions{ChunkSize: 2})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, deploymentRequests)
+		assert.Equal(t, 3, report.Requests)
+		assert.Equal(t, [][]string{
+			{"/external-dns-name/a.example.com", "/external-dns-name/b.example.com"},
+			{"/external-dns-name/c.example.com", "/external-dns-name/d.example.com"},
+			{"/external-dns-name/e.example.com"},
+		}, *batches)
+		assert.Empty(t, report.Failed())
+		assert.Equal(t, AssetBatchResult{
+			Request: ExternalDNSAssetRequest{
+				Operation: "remove_asset",
+				Type:      "external-dns-name",
+				Scope:     "datacenter",
+				Key:       "/external-dns-name/c.example.com",
+			},
+			StatusCode: http.StatusNoContent,
+		}, report.Results[2])
+		assert.Equal(t, "aws", report.Results[4].Request.Scope)
+	}
+}
+
+func TestAssetsBatch_WriteAssetBatchSplitsTooLarge(t *testing.T) {
+	setup()
+	defer teardown()
+
+	batches := batchHandler(t, 1, "")
+
+	requests := []ExternalDNSAssetRequest{}
+	for _, name := range []string{"a", "b", "c"} {
+		request := declareDNSName(t, name+".example.com")
+		request.Scope = "aws"
+		requests = append(requests, request)
+	}
+
+	report, err := client.WriteAssetBatch(testDeploymentId, requests, AssetBatchOptions{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, report.Requests)
+		assert.Equal(t, [][]string{
+			{"/external-dns-name/a.example.com", "/external-dns-name/b.example.com", "/external-dns-name/c.example.com"},
+			{"/external-dns-name/a.example.com"},
+			{"/external-dns-name/b.example.com", "/external-dns-name/c.example.com"},
+			{"/external-dns-name/b.example.com"},
+			{"/external-dns-name/c.example.com"},
+		}, *batches)
+		for _, result := range report.Results {
+			assert.Equal(t, http.StatusNoContent, result.StatusCode)
+		}
+	}
+}
+
+func TestAssetsBatch_WriteAssetBatchFailures(t *testing.T) {
+	setup()
+	defer teardown()
+
+	batchHandler(t, 0, "/external-dns-name/b.example.com")
+
+	requests := []ExternalDNSAssetRequest{
+		{Operation: "declare_asset", Type: "external-dns-name", Scope: "aws"},
+		declareDNSName(t, "a.example.com"),
+		declareDNSName(t, "b.example.com"),
+		declareDNSName(t, "c.example.com"),
+	}
+	for i := range requests {
+		requests[i].Scope = "aws"
+	}
+
+	report, err := client.WriteAssetBatch(testDeploymentId, requests, AssetBatchOptions{ChunkSize: 2})
+
+	assert.EqualError(t, err, "4 of 4 asset operations failed")
+	assert.Equal(t, 2, report.Requests)
+	assert.Equal(t, "asset operation, type and key must not be empty", report.Results[0].Error)
+	assert.Equal(t, 0, report.Results[0].StatusCode)
+	assert.Equal(t, http.StatusBadRequest, report.Results[1].StatusCode)
+	assert.Equal(t, `error from makeRequest: {"error": "invalid asset"}`, report.Results[2].Error)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, report.Results[3].StatusCode)
+	assert.True(t, report.Results[3].Failed())
+}
+
+func TestAssetsBatch_WriteAssetBatchScopeError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.WriteAssetBatch(testDeploymentId, []ExternalDNSAssetRequest{declareDNSName(t, "a.example.com")}, AssetBatchOptions{})
+
+	assert.Contains(t, err.Error(), "error inferring asset scope")
+}
+
+func TestAssetsBatch_WriteAssetBatchInvalidDNSNames(t *testing.T) {
+	setup()
+	defer teardown()
+
+	batches := batchHandler(t, 10, "")
+
+	_, err := NewDeclareExternalDNSNameRequest("*.example.com")
+	assert.EqualError(t, err, `invalid DNS name "*.example.com": wildcard names cannot be declared`)
+	_, err = NewRemoveExternalDNSNameRequest("*.example.com")
+	assert.EqualError(t, err, `invalid DNS name "*.example.com": wildcard names cannot be declared`)
+
+	requests := []ExternalDNSAssetRequest{
+		declareDNSName(t, "a.example.com"),
+		{Operation: "declare_asset", Type: "external-dns-name", Key: "/external-dns-name/*.example.com", Properties: map[string]string{"dns_name": "*.example.com"}},
+		{Operation: "declare_asset", Type: "external-dns-name", Key: "/external-dns-name/B.example.com", Properties: map[string]string{"dns_name": "B.example.com"}},
+		{Operation: "remove_asset", Type: "external-dns-name", Key: "/external-dns-name/*.example.com"},
+	}
+	for i := range requests {
+		requests[i].Scope = "aws"
+	}
+
+	report, err := client.WriteAssetBatch(testDeploymentId, requests, AssetBatchOptions{})
+
+	assert.EqualError(t, err, "2 of 4 asset operations failed")
+	assert.Equal(t, [][]string{{"/external-dns-name/a.example.com", "/external-dns-name/*.example.com"}}, *batches)
+	assert.Equal(t, `invalid DNS name "*.example.com": wildcard names cannot be declared`, report.Results[1].Error)
+	assert.Equal(t, "key /external-dns-name/B.example.com does not match the normalized key /external-dns-name/b.example.com", report.Results[2].Error)
+	assert.Equal(t, 0, report.Results[2].StatusCode)
+}
+
+func TestAssetsBatch_AssetBatchChunks(t *testing.T) {
+	sizes := map[int]int{0: 10, 1: 10, 2: 30, 3: 5, 4: 5}
+
+	assert.Equal(t, [][]int{{0, 1}, {2}, {3, 4}}, assetBatchChunks([]int{0, 1, 2, 3, 4}, sizes, AssetBatchOptions{ChunkSize: 10, ChunkBytes: 25}))
+	assert.Equal(t, [][]int{{0}, {1}, {2}, {3}, {4}}, assetBatchChunks([]int{0, 1, 2, 3, 4}, sizes, AssetBatchOptions{ChunkSize: 1, ChunkBytes: 100}))
+	assert.Equal(t, [][]int{}, assetBatchChunks(nil, sizes, AssetBatchOptions{ChunkSize: 1, ChunkBytes: 100}))
+}
@@ -0,0 +1,122 @@
+package alertlogic
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	// maxDNSNameLength is the maximum length of a DNS name without its trailing dot.
+	maxDNSNameLength = 253
+	// maxDNSLabelLength is the maximum length of a single label of a DNS name.
+	maxDNSLabelLength = 63
+)
+
+// dnsLabelSeparators replaces the full stops that IDNA treats as label separators with dots.
+var dnsLabelSeparators = strings.NewReplacer("\u3002", ".", "\uff0e", ".", "\uff61", ".")
+
+// NormalizeDNSName validates a DNS name and returns it in the form used in external DNS name asset
+// keys: lower case, without a trailing dot, and with internationalized labels converted to
+// punycode, for example `Bücher.Example.com.` becomes `xn--bcher-kva.example.com`.
+// Every label must be 1 to 63 letters, digits and hyphens, not starting or ending with a hyphen,
+// and the name must be at most 253 characters. Wildcards are rejected because they cannot be
+// scanned, and names whose last label is all digits, such as IPv4 addresses, are rejected as
+// RFC 3696 requires; declare IP addresses with ExternalIPDeclaration instead. Labels that are not
+// ASCII, or that start with `xn--`, are mapped and validated with the IDNA lookup profile, so
+// equivalent spellings of a name normalize to the same key, and must only contain letters,
+// combining marks and digits.
+// The returned error is a *DNSNameError.
+func NormalizeDNSName(name string) (string, error) {
+	original := name
+	name = strings.TrimSuffix(dnsLabelSeparators.Replace(strings.TrimSpace(name)), ".")
+	if name == "" {
+		return "", &DNSNameError{Name: original, Kind: DNSNameEmpty}
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if label == "" {
+			return "", &DNSNameError{Name: original, Kind: DNSNameEmptyLabel}
+		}
+		if strings.Contains(label, "*") {
+			return "", &DNSNameError{Name: original, Label: label, Kind: DNSNameWildcard}
+		}
+
+		label = strings.ToLower(label)
+		if !isASCII(label) || strings.HasPrefix(label, "xn--") {
+			encoded, err := idna.Lookup.ToASCII(label)
+			if err != nil || strings.Contains(encoded, ".") || !isIDNALabel(encoded) {
+				return "", &DNSNameError{Name: original, Label: labels[i], Kind: DNSNameInvalidIDN}
+			}
+			label = encoded
+		}
+
+		if len(label) > maxDNSLabelLength {
+			return "", &DNSNameError{Name: original, Label: labels[i], Kind: DNSNameLabelTooLong}
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return "", &DNSNameError{Name: original, Label: labels[i], Kind: DNSNameInvalidCharacter}
+			}
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return "", &DNSNameError{Name: original, Label: labels[i], Kind: DNSNameInvalidHyphen}
+		}
+
+		labels[i] = label
+	}
+
+	if isNumeric(labels[len(labels)-1]) {
+		return "", &DNSNameError{Name: original, Label: labels[len(labels)-1], Kind: DNSNameNumeric}
+	}
+
+	name = strings.Join(labels, ".")
+	if len(name) > maxDNSNameLength {
+		return "", &DNSNameError{Name: original, Kind: DNSNameTooLong}
+	}
+
+	return name, nil
+}
+
+// isNumeric reports whether `label` only contains digits.
+func isNumeric(label string) bool {
+	for i := 0; i < len(label); i++ {
+		if label[i] < '0' || label[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isIDNALabel reports whether the punycode label `label` only contains letters, combining marks,
+// digits, hyphens and joiners. The lookup profile accepts symbols such as emoji, which IDNA2008
+// disallows.
+func isIDNALabel(label string) bool {
+	decoded, err := idna.Lookup.ToUnicode(label)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range decoded {
+		if r != '-' && r != '\u200c' && r != '\u200d' && !unicode.In(r, unicode.L, unicode.M, unicode.Nd) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isASCII reports whether `s` only contains ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,142 @@
+package alertlogic
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetsDNSName_NormalizeDNSName(t *testing.T) {
+	var normalizeTests = []struct {
+		name       string
+		normalized string
+	}{
+		{"www.example.com", "www.example.com"},
+		{" WWW.Example.COM. ", "www.example.com"},
+		{"Bücher.example.com", "xn--bcher-kva.example.com"},
+		{"München.DE", "xn--mnchen-3ya.de"},
+		{"例え.テスト", "xn--r8jz45g.xn--zckzah"},
+		{"例え。テスト", "xn--r8jz45g.xn--zckzah"},
+		{"Bu\u0308cher.example.com", "xn--bcher-kva.example.com"},
+		{"XN--BCHER-KVA.example.com", "xn--bcher-kva.example.com"},
+		{"xn--bcher-kva.example.com", "xn--bcher-kva.example.com"},
+		{"1-2-3.example.com", "1-2-3.example.com"},
+		{"1.2.3.4.example.com", "1.2.3.4.example.com"},
+		{"localhost", "localhost"},
+		{strings.Repeat("a", 63) + ".com", strings.Repeat("a", 63) + ".com"},
+	}
+
+	for _, tt := range normalizeTests {
+		normalized, err := NormalizeDNSName(tt.name)
+
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, tt.normalized, normalized)
+		}
+	}
+}
+
+func TestAssetsDNSName_NormalizeDNSNameErrors(t *testing.T) {
+	longName := strings.Repeat(strings.Repeat("a", 63)+".", 4) + "com"
+
+	var errorTests = []struct {
+		name string
+		kind DNSNameErrorKind
+		err  string
+	}{
+		{"", DNSNameEmpty, `invalid DNS name "": name is empty`},
+		{".", DNSNameEmpty, `invalid DNS name ".": name is empty`},
+		{"www..example.com", DNSNameEmptyLabel, `invalid DNS name "www..example.com": name has an empty label`},
+		{"*.example.com", DNSNameWildcard, `invalid DNS name "*.example.com": wildcard names cannot be declared`},
+		{"www*.example.com", DNSNameWildcard, `invalid DNS name "www*.example.com": wildcard names cannot be declared`},
+		{"_dmarc.example.com", DNSNameInvalidCharacter, `invalid DNS name "_dmarc.example.com": label "_dmarc" contains characters other than letters, digits and hyphens`},
+		{"http://example.com", DNSNameInvalidCharacter, `invalid DNS name "http://example.com": label "http://example" contains characters other than letters, digits and hyphens`},
+		{"-www.example.com", DNSNameInvalidHyphen, `invalid DNS name "-www.example.com": label "-www" starts or ends with a hyphen`},
+		{strings.Repeat("a", 64) + ".com", DNSNameLabelTooLong, `invalid DNS name "` + strings.Repeat("a", 64) + `.com": label "` + strings.Repeat("a", 64) + `" is longer than 63 characters`},
+		{strings.Repeat("ü", 60) + ".com", DNSNameLabelTooLong, ""},
+		{"1.2.3.4", DNSNameNumeric, `invalid DNS name "1.2.3.4": top-level label is numeric; declare IP addresses with ExternalIPDeclaration`},
+		{"www.example.123", DNSNameNumeric, ""},
+		{"😀.com", DNSNameInvalidIDN, `invalid DNS name "😀.com": label "😀" is not a valid internationalized label`},
+		{"xn--a.example.com", DNSNameInvalidIDN, ""},
+		{"bü_cher.example.com", DNSNameInvalidIDN, ""},
+		{longName, DNSNameTooLong, `invalid DNS name "` + longName + `": name is longer than 253 characters`},
+	}
+
+	for _, tt := range errorTests {
+		_, err := NormalizeDNSName(tt.name)
+
+		var dnsNameError *DNSNameError
+		if assert.True(t, errors.As(err, &dnsNameError), tt.name) {
+			assert.Equal(t, tt.kind, dnsNameError.Kind)
+			assert.Equal(t, tt.name, dnsNameError.Name)
+		}
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+		}
+	}
+}
+
+func TestAssetsDNSName_ExternalDNSNameAssetsAreNormalized(t *testing.T) {
+	setup()
+	defer teardown()
+
+	keys := []string{}
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		keys = append(keys, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.CreateExternalDNSNameAsset(testDeploymentId, "WWW.Bücher.example.com.")
+	assert.NoError(t, err)
+	_, err = client.UpdateExternalDNSNameAsset(testDeploymentId, "New.example.com", "OLD.example.com.")
+	assert.NoError(t, err)
+	_, err = client.RemoveExternalDNSNameAsset(testDeploymentId, "Old.Example.com")
+	assert.NoError(t, err)
+	_, err = client.TagExternalDNSNameAsset(testDeploymentId, "WWW.example.com", Tags{"env": "prod"})
+	assert.NoError(t, err)
+
+	if assert.Len(t, keys, 4) {
+		assert.Contains(t, keys[0], `"key":"/external-dns-name/www.xn--bcher-kva.example.com"`)
+		assert.Contains(t, keys[0], `"dns_name":"www.xn--bcher-kva.example.com"`)
+		assert.Contains(t, keys[1], `"key":"/external-dns-name/old.example.com"`)
+		assert.Contains(t, keys[1], `"dns_name":"new.example.com"`)
+		assert.Contains(t, keys[2], `"key":"/external-dns-name/old.example.com"`)
+		assert.Contains(t, keys[3], `"key":"/external-dns-name/www.example.com"`)
+	}
+
+	for _, call := range []func() (int, error){
+		func() (int, error) { return client.CreateExternalDNSNameAsset(testDeploymentId, "*.example.com") },
+		func() (int, error) {
+			return client.UpdateExternalDNSNameAsset(testDeploymentId, "*.example.com", "www.example.com")
+		},
+		func() (int, error) {
+			return client.UpdateExternalDNSNameAsset(testDeploymentId, "www.example.com", "*.example.com")
+		},
+		func() (int, error) { return client.RemoveExternalDNSNameAsset(testDeploymentId, "*.example.com") },
+		func() (int, error) { return client.UntagExternalDNSNameAsset(testDeploymentId, "*.example.com", "env") },
+	} {
+		_, err := call()
+
+		var dnsNameError *DNSNameError
+		if assert.True(t, errors.As(err, &dnsNameError)) {
+			assert.Equal(t, DNSNameWildcard, dnsNameError.Kind)
+		}
+	}
+	assert.Len(t, keys, 4)
+}
+
+func TestAssetsDNSName_ZoneDNSNames(t *testing.T) {
+	records := []ZoneRecord{
+		{Name: "bücher.example.com", Type: "A"},
+		{Name: "_acme-challenge.example.com", Type: "CNAME"},
+		{Name: "*.example.com", Type: "A"},
+		{Name: "xn--bcher-kva.example.com", Type: "AAAA"},
+	}
+
+	assert.Equal(t, []string{"xn--bcher-kva.example.com"}, ZoneDNSNames(records))
+}
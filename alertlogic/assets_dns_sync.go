@@ -0,0 +1,424 @@
+package alertlogic
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxDNSRemovals is the default maximum number of external DNS names a DNSSyncPlan may
+// remove before ApplyDNSSyncPlan refuses to apply it.
+const DefaultMaxDNSRemovals = 10
+
+// ZoneRecord is a single resource record from a zone file. `Name` is the fully qualified owner
+// name in lower case, without a trailing dot.
+type ZoneRecord struct {
+	Name string
+	Type string
+	Data string
+}
+
+// DNSSyncAction is a change made by a DNSSyncPlan.
+type DNSSyncAction string
+
+const (
+	CreateDNSNameAction DNSSyncAction = "create"
+	RemoveDNSNameAction DNSSyncAction = "remove"
+)
+
+// DNSSyncStep is a single change in a DNSSyncPlan. For a removal, `DNSName` is the name as stored
+// in the asset and `Key` is the asset's key, which is removed as is. It differs from the
+// normalized key of the name for assets declared before names were normalized.
+type DNSSyncStep struct {
+	Action  DNSSyncAction `json:"action"`
+	DNSName string        `json:"dns_name"`
+	Key     string        `json:"key,omitempty"`
+}
+
+// String returns a human-readable description of the step.
+func (s DNSSyncStep) String() string {
+	switch s.Action {
+	case CreateDNSNameAction:
+		return fmt.Sprintf("+ create external DNS name %s", s.DNSName)
+	case RemoveDNSNameAction:
+		return fmt.Sprintf("- remove external DNS name %s", s.DNSName)
+	}
+
+	return fmt.Sprintf("? %s %s", s.Action, s.DNSName)
+}
+
+// DNSSyncPlan is the set of changes needed to make a deployment's external DNS name assets match
+// a list of DNS names. `Unchanged` is the number of names that already match.
+type DNSSyncPlan struct {
+	DeploymentID string        `json:"deployment_id"`
+	Steps        []DNSSyncStep `json:"steps"`
+	Unchanged    int           `json:"unchanged"`
+}
+
+// Empty reports whether the plan has no changes.
+func (p DNSSyncPlan) Empty() bool {
+	return len(p.Steps) == 0
+}
+
+// Removals returns the number of names the plan removes.
+func (p DNSSyncPlan) Removals() int {
+	removals := 0
+	for _, step := range p.Steps {
+		if step.Action == RemoveDNSNameAction {
+			removals++
+		}
+	}
+
+	return removals
+}
+
+// String returns a human-readable description of the plan, one step per line.
+func (p DNSSyncPlan) String() string {
+	if p.Empty() {
+		return "no changes"
+	}
+
+	lines := make([]string, len(p.Steps))
+	for i, step := range p.Steps {
+		lines[i] = step.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// DNSSyncOptions control ApplyDNSSyncPlan and SyncDNSZone.
+// With `DryRun` the plan is checked but not applied. `MaxRemovals` limits how many names a plan
+// may remove; zero means DefaultMaxDNSRemovals and a negative value means no limit.
+type DNSSyncOptions struct {
+	DryRun      bool
+	MaxRemovals int
+}
+
+// DNSSyncStepError is a step of a DNSSyncPlan that failed to apply.
+type DNSSyncStepError struct {
+	Step DNSSyncStep
+	Err  error
+}
+
+// Error returns the error message including the failed step.
+func (e DNSSyncStepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step, e.Err)
+}
+
+// DNSSyncResult holds a DNSSyncPlan and the outcome of applying it. Nothing is applied for a dry
+// run.
+type DNSSyncResult struct {
+	Plan    DNSSyncPlan
+	DryRun  bool
+	Applied []DNSSyncStep
+	Failed  []DNSSyncStepError
+}
+
+// ParseZoneFile parses the resource records of a BIND zone file. `origin` is the initial origin
+// for relative names and may be empty if the file sets one with `$ORIGIN`.
+// Comments, parentheses spanning several lines, `@`, owner names carried over from the previous
+// record, and TTL and class fields are supported. `$INCLUDE` and `$GENERATE` are not.
+func ParseZoneFile(r io.Reader, origin string) ([]ZoneRecord, error) {
+	origin = absoluteDNSName(origin, ".")
+	records := []ZoneRecord{}
+	owner := ""
+
+	scanner := bufio.NewScanner(r)
+	lineNumber, depth := 0, 0
+	entry, entryLine := "", 0
+	for scanner.Scan() {
+		lineNumber++
+		line, opened := stripZoneComment(scanner.Text())
+
+		if depth == 0 {
+			entry, entryLine = line, lineNumber
+		} else {
+			entry += " " + line
+		}
+		depth += opened
+		if depth < 0 {
+			return nil, errors.Errorf("unbalanced parentheses on line %d", lineNumber)
+		}
+		if depth > 0 {
+			continue
+		}
+
+		entry = strings.NewReplacer("(", " ", ")", " ").Replace(entry)
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(fields[0], "$") {
+			switch strings.ToUpper(fields[0]) {
+			case "$ORIGIN":
+				if len(fields) < 2 {
+					return nil, errors.Errorf("$ORIGIN without a name on line %d", entryLine)
+				}
+				if origin == "" {
+					origin = absoluteDNSName(fields[1], ".")
+				} else {
+					origin = absoluteDNSName(fields[1], origin)
+				}
+			case "$TTL":
+			default:
+				return nil, errors.Errorf("unsupported directive %s on line %d", fields[0], entryLine)
+			}
+			continue
+		}
+
+		if entry[0] != ' ' && entry[0] != '\t' {
+			name := fields[0]
+			fields = fields[1:]
+			if name == "@" {
+				owner = origin
+			} else {
+				owner = absoluteDNSName(name, origin)
+			}
+			if !strings.HasSuffix(owner, ".") {
+				return nil, errors.Errorf("relative name %q without an origin on line %d", name, entryLine)
+			}
+		}
+		if owner == "" {
+			return nil, errors.Errorf("record without an owner name on line %d", entryLine)
+		}
+
+		for skipped := 0; len(fields) > 0 && skipped < 2 && (isZoneTTL(fields[0]) || isZoneClass(fields[0])); skipped++ {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			return nil, errors.Errorf("record without a type on line %d", entryLine)
+		}
+
+		records = append(records, ZoneRecord{
+			Name: strings.ToLower(strings.TrimSuffix(owner, ".")),
+			Type: strings.ToUpper(fields[0]),
+			Data: strings.Join(fields[1:], " "),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error reading zone file")
+	}
+	if depth > 0 {
+		return nil, errors.Errorf("unbalanced parentheses on line %d", entryLine)
+	}
+
+	return records, nil
+}
+
+// ZoneDNSNames returns the sorted, distinct owner names of the A, AAAA and CNAME records in
+// `records`, normalized with NormalizeDNSName. Names that are not valid host names, such as
+// wildcards and names with underscores, are left out, since they cannot be scanned.
+func ZoneDNSNames(records []ZoneRecord) []string {
+	names := map[string]bool{}
+	for _, record := range records {
+		switch record.Type {
+		case "A", "AAAA", "CNAME":
+		default:
+			continue
+		}
+		name, err := NormalizeDNSName(record.Name)
+		if err != nil {
+			continue
+		}
+		names[name] = true
+	}
+
+	return sortedKeys(names)
+}
+
+// PlanDNSSync compares `dnsNames` with the external DNS name assets of a deployment and returns
+// the changes needed to make them match. Names are compared after NormalizeDNSName, and an
+// invalid name in `dnsNames` fails the plan. Nothing is changed in the account.
+// Assets whose key is not the normalized key of their name, such as assets declared with a
+// trailing dot or in upper case, are removed, and a wanted name is declared again under its
+// normalized key. Assets with names that are not valid are removed.
+func (api *API) PlanDNSSync(deploymentId string, dnsNames []string) (DNSSyncPlan, error) {
+	assets, err := api.GetExternalDNSNameAssets()
+	if err != nil {
+		return DNSSyncPlan{}, err
+	}
+
+	live := map[string]bool{}
+	liveSteps := map[string][]DNSSyncStep{}
+	for _, row := range assets.ExternalDNSAssets {
+		for _, asset := range row {
+			if asset.DeploymentID != deploymentId || asset.DeletedOn != 0 {
+				continue
+			}
+			name, err := NormalizeDNSName(asset.DNSName)
+			if err != nil {
+				name = asset.DNSName
+			}
+			key := asset.Key
+			if key == "" {
+				key = externalDNSNameKey(asset.DNSName)
+			}
+			live[name] = true
+			liveSteps[name] = append(liveSteps[name], DNSSyncStep{Action: RemoveDNSNameAction, DNSName: asset.DNSName, Key: key})
+		}
+	}
+
+	desired := map[string]bool{}
+	for _, name := range dnsNames {
+		normalized, err := NormalizeDNSName(name)
+		if err != nil {
+			return DNSSyncPlan{}, err
+		}
+		desired[normalized] = true
+	}
+
+	plan := DNSSyncPlan{DeploymentID: deploymentId, Steps: []DNSSyncStep{}}
+	removals := []DNSSyncStep{}
+	for _, name := range sortedKeys(desired) {
+		found := false
+		for _, step := range liveSteps[name] {
+			if step.Key == externalDNSNameKey(name) {
+				found = true
+			}
+		}
+		if found {
+			plan.Unchanged++
+		} else {
+			plan.Steps = append(plan.Steps, DNSSyncStep{Action: CreateDNSNameAction, DNSName: name})
+		}
+	}
+	for _, name := range sortedKeys(live) {
+		steps := liveSteps[name]
+		sort.Slice(steps, func(i, j int) bool { return steps[i].Key < steps[j].Key })
+		for _, step := range steps {
+			if !desired[name] || step.Key != externalDNSNameKey(name) {
+				removals = append(removals, step)
+			}
+		}
+	}
+	plan.Steps = append(plan.Steps, removals...)
+
+	return plan, nil
+}
+
+// ApplyDNSSyncPlan applies the steps of `plan` in order using CreateExternalDNSNameAsset, and
+// RemoveExternalDNSNameAssetByKey or, for removals without a key, RemoveExternalDNSNameAsset.
+// A plan that removes more names than `options.MaxRemovals` is refused without changing
+// anything, including for a dry run.
+// A failed step does not stop the remaining steps. The returned error is non-nil if any step
+// failed, and `DNSSyncResult.Failed` lists each of them.
+func (api *API) ApplyDNSSyncPlan(plan DNSSyncPlan, options DNSSyncOptions) (DNSSyncResult, error) {
+	result := DNSSyncResult{Plan: plan, DryRun: options.DryRun}
+
+	maxRemovals := options.MaxRemovals
+	if maxRemovals == 0 {
+		maxRemovals = DefaultMaxDNSRemovals
+	}
+	if removals := plan.Removals(); maxRemovals > 0 && removals > maxRemovals {
+		return result, errors.Errorf("plan removes %d external DNS names, more than the limit of %d", removals, maxRemovals)
+	}
+
+	if options.DryRun {
+		return result, nil
+	}
+
+	for _, step := range plan.Steps {
+		var err error
+		switch step.Action {
+		case CreateDNSNameAction:
+			_, err = api.CreateExternalDNSNameAsset(plan.DeploymentID, step.DNSName)
+		case RemoveDNSNameAction:
+			if step.Key != "" {
+				_, err = api.RemoveExternalDNSNameAssetByKey(plan.DeploymentID, step.Key)
+			} else {
+				_, err = api.RemoveExternalDNSNameAsset(plan.DeploymentID, step.DNSName)
+			}
+		default:
+			err = errors.Errorf("unknown action %q", step.Action)
+		}
+
+		if err != nil {
+			result.Failed = append(result.Failed, DNSSyncStepError{Step: step, Err: err})
+			continue
+		}
+		result.Applied = append(result.Applied, step)
+	}
+
+	if len(result.Failed) > 0 {
+		return result, errors.Errorf("%d of %d DNS sync steps failed", len(result.Failed), len(plan.Steps))
+	}
+
+	return result, nil
+}
+
+// SyncDNSZone makes a deployment's external DNS name assets match the A, AAAA and CNAME records
+// of a BIND zone file. See ParseZoneFile, PlanDNSSync and ApplyDNSSyncPlan.
+func (api *API) SyncDNSZone(deploymentId string, zone io.Reader, origin string, options DNSSyncOptions) (DNSSyncResult, error) {
+	records, err := ParseZoneFile(zone, origin)
+	if err != nil {
+		return DNSSyncResult{}, err
+	}
+
+	plan, err := api.PlanDNSSync(deploymentId, ZoneDNSNames(records))
+	if err != nil {
+		return DNSSyncResult{}, err
+	}
+
+	return api.ApplyDNSSyncPlan(plan, options)
+}
+
+// stripZoneComment removes a comment from a zone file line, ignoring semicolons in quoted
+// strings, and returns the line and the number of parentheses it opens, less those it closes.
+func stripZoneComment(line string) (string, int) {
+	quoted, opened := false, 0
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';':
+			return line[:i], opened
+		case c == '(':
+			opened++
+		case c == ')':
+			opened--
+		}
+	}
+
+	return line, opened
+}
+
+// absoluteDNSName makes `name` absolute by appending `origin` unless it ends with a dot. Names
+// stay relative if `origin` is empty.
+func absoluteDNSName(name string, origin string) string {
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	if origin == "" {
+		return name
+	}
+	if origin == "." {
+		return name + "."
+	}
+
+	return name + "." + origin
+}
+
+// isZoneTTL reports whether a record field is a TTL, such as `3600` or `1h30m`.
+func isZoneTTL(field string) bool {
+	if field == "" || field[0] < '0' || field[0] > '9' {
+		return false
+	}
+
+	return strings.Trim(strings.ToLower(field), "0123456789smhdw") == ""
+}
+
+// isZoneClass reports whether a record field is a DNS class.
+func isZoneClass(field string) bool {
+	switch strings.ToUpper(field) {
+	case "IN", "CH", "HS", "CS":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,242 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testZoneFile = `
+$TTL 3600
+$ORIGIN example.com.
+@	IN	SOA	ns1.example.com. hostmaster.example.com. (
+		2022010101 ; serial
+		7200       ; refresh
+		3600 1209600 3600 )
+	IN	NS	ns1.example.com.
+	IN	A	203.0.113.1
+www	300	IN	A	203.0.113.10 ; web server
+	IN	AAAA	2001:db8::10
+API	IN 60	CNAME	lb-1.example.net.
+*.apps	IN	A	203.0.113.20
+mail	IN	MX	10 mx.example.com.
+txt	IN	TXT	"v=spf1 -all; not a comment"
+$ORIGIN internal
+db	A	10.0.0.5
+Shop.Example.ORG.	1h30m	A	198.51.100.1
+`
+
+func TestAssetsDNSSync_ParseZoneFile(t *testing.T) {
+	records, err := ParseZoneFile(strings.NewReader(testZoneFile), "")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []ZoneRecord{
+			{Name: "example.com", Type: "SOA", Data: "ns1.example.com. hostmaster.example.com. 2022010101 7200 3600 1209600 3600"},
+			{Name: "example.com", Type: "NS", Data: "ns1.example.com."},
+			{Name: "example.com", Type: "A", Data: "203.0.113.1"},
+			{Name: "www.example.com", Type: "A", Data: "203.0.113.10"},
+			{Name: "www.example.com", Type: "AAAA", Data: "2001:db8::10"},
+			{Name: "api.example.com", Type: "CNAME", Data: "lb-1.example.net."},
+			{Name: "*.apps.example.com", Type: "A", Data: "203.0.113.20"},
+			{Name: "mail.example.com", Type: "MX", Data: "10 mx.example.com."},
+			{Name: "txt.example.com", Type: "TXT", Data: `"v=spf1 -all; not a comment"`},
+			{Name: "db.internal.example.com", Type: "A", Data: "10.0.0.5"},
+			{Name: "shop.example.org", Type: "A", Data: "198.51.100.1"},
+		}, records)
+
+		assert.Equal(t, []string{
+			"api.example.com",
+			"db.internal.example.com",
+			"example.com",
+			"shop.example.org",
+			"www.example.com",
+		}, ZoneDNSNames(records))
+	}
+
+	records, err = ParseZoneFile(strings.NewReader("www A 203.0.113.10\n@ A 203.0.113.1\n"), "example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"example.com", "www.example.com"}, ZoneDNSNames(records))
+	}
+}
+
+func TestAssetsDNSSync_ParseZoneFileErrors(t *testing.T) {
+	var zoneTests = []struct {
+		zone string
+		err  string
+	}{
+		{"www A 203.0.113.10\n", `relative name "www" without an origin on line 1`},
+		{"$ORIGIN example.com.\n\tA 203.0.113.10\n", "record without an owner name on line 2"},
+		{"$INCLUDE other.zone\n", "unsupported directive $INCLUDE on line 1"},
+		{"$ORIGIN\n", "$ORIGIN without a name on line 1"},
+		{"www.example.com. 300 IN\n", "record without a type on line 1"},
+		{"@ SOA ns1 hostmaster (\n1 2 3\n", "unbalanced parentheses on line 1"},
+		{"www.example.com. A 203.0.113.10 )\n", "unbalanced parentheses on line 1"},
+	}
+
+	for _, tt := range zoneTests {
+		_, err := ParseZoneFile(strings.NewReader(tt.zone), "")
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+// dnsSyncHandlers serves external DNS name assets in two deployments and records every write as
+// "operation key".
+func dnsSyncHandlers(t *testing.T, failKey string) *[]string {
+	var mu sync.Mutex
+	writes := []string{}
+
+	mux.HandleFunc(getExternalDNSNameAssetsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"rows": 6, "assets": [
+			[{"type": "external-dns-name", "key": "/external-dns-name/www.example.com", "dns_name": "www.example.com", "deployment_id": "%[1]s"}],
+			[{"type": "external-dns-name", "key": "/external-dns-name/Old.Example.com", "dns_name": "Old.Example.com", "deployment_id": "%[1]s"}],
+			[{"type": "external-dns-name", "key": "/external-dns-name/Example.COM.", "dns_name": "Example.COM.", "deployment_id": "%[1]s"}],
+			[{"type": "external-dns-name", "key": "/external-dns-name/*.example.com", "dns_name": "*.example.com", "deployment_id": "%[1]s"}],
+			[{"type": "external-dns-name", "dns_name": "gone.example.com", "deployment_id": "%[1]s", "deleted_on": 1640995200}],
+			[{"type": "external-dns-name", "dns_name": "other.example.com", "deployment_id": "ABCDEFGH-ABCD-ABCD-ABCD-ABCDEFGHIJKL"}]
+		]}`, testDeploymentId)
+	})
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		var request struct {
+			Operation string `json:"operation"`
+			Key       string `json:"key"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &request))
+
+		writes = append(writes, request.Operation+" "+request.Key)
+		if request.Key == failKey {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return &writes
+}
+
+func TestAssetsDNSSync_SyncDNSZone(t *testing.T) {
+	setup()
+	defer teardown()
+
+	writes := dnsSyncHandlers(t, "")
+
+	result, err := client.SyncDNSZone(testDeploymentId, strings.NewReader(testZoneFile), "", DNSSyncOptions{})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.Join([]string{
+			"+ create external DNS name api.example.com",
+			"+ create external DNS name db.internal.example.com",
+			"+ create external DNS name example.com",
+			"+ create external DNS name shop.example.org",
+			"- remove external DNS name *.example.com",
+			"- remove external DNS name Example.COM.",
+			"- remove external DNS name Old.Example.com",
+		}, "\n"), result.Plan.String())
+		assert.Equal(t, 1, result.Plan.Unchanged)
+		assert.Equal(t, 3, result.Plan.Removals())
+		assert.Len(t, result.Applied, 7)
+		assert.Empty(t, result.Failed)
+		assert.Equal(t, []string{
+			"declare_asset /external-dns-name/api.example.com",
+			"declare_asset /external-dns-name/db.internal.example.com",
+			"declare_asset /external-dns-name/example.com",
+			"declare_asset /external-dns-name/shop.example.org",
+			"remove_asset /external-dns-name/*.example.com",
+			"remove_asset /external-dns-name/Example.COM.",
+			"remove_asset /external-dns-name/Old.Example.com",
+		}, *writes)
+	}
+}
+
+func TestAssetsDNSSync_SyncDNSZoneDryRun(t *testing.T) {
+	setup()
+	defer teardown()
+
+	writes := dnsSyncHandlers(t, "")
+
+	result, err := client.SyncDNSZone(testDeploymentId, strings.NewReader("www.example.com. A 203.0.113.10\n"), "", DNSSyncOptions{DryRun: true})
+
+	if assert.NoError(t, err) {
+		assert.True(t, result.DryRun)
+		assert.Equal(t, strings.Join([]string{
+			"- remove external DNS name *.example.com",
+			"- remove external DNS name Example.COM.",
+			"- remove external DNS name Old.Example.com",
+		}, "\n"), result.Plan.String())
+		assert.Empty(t, result.Applied)
+		assert.Empty(t, *writes)
+	}
+
+	plan, err := client.PlanDNSSync(testDeploymentId, []string{"WWW.example.com.", "old.example.com", "example.com"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []DNSSyncStep{
+			{Action: CreateDNSNameAction, DNSName: "example.com"},
+			{Action: CreateDNSNameAction, DNSName: "old.example.com"},
+			{Action: RemoveDNSNameAction, DNSName: "*.example.com", Key: "/external-dns-name/*.example.com"},
+			{Action: RemoveDNSNameAction, DNSName: "Example.COM.", Key: "/external-dns-name/Example.COM."},
+			{Action: RemoveDNSNameAction, DNSName: "Old.Example.com", Key: "/external-dns-name/Old.Example.com"},
+		}, plan.Steps)
+		assert.Equal(t, 1, plan.Unchanged)
+	}
+	assert.Equal(t, "no changes", DNSSyncPlan{}.String())
+
+	_, err = client.PlanDNSSync(testDeploymentId, []string{"*.example.com"})
+	assert.EqualError(t, err, `invalid DNS name "*.example.com": wildcard names cannot be declared`)
+}
+
+func TestAssetsDNSSync_ApplyDNSSyncPlanGuardsRemovals(t *testing.T) {
+	setup()
+	defer teardown()
+
+	writes := dnsSyncHandlers(t, "")
+
+	plan := DNSSyncPlan{DeploymentID: testDeploymentId}
+	for i := 0; i < DefaultMaxDNSRemovals+1; i++ {
+		plan.Steps = append(plan.Steps, DNSSyncStep{Action: RemoveDNSNameAction, DNSName: fmt.Sprintf("host-%d.example.com", i)})
+	}
+
+	_, err := client.ApplyDNSSyncPlan(plan, DNSSyncOptions{})
+	assert.EqualError(t, err, "plan removes 11 external DNS names, more than the limit of 10")
+
+	_, err = client.ApplyDNSSyncPlan(plan, DNSSyncOptions{DryRun: true, MaxRemovals: 5})
+	assert.EqualError(t, err, "plan removes 11 external DNS names, more than the limit of 5")
+	assert.Empty(t, *writes)
+
+	result, err := client.ApplyDNSSyncPlan(plan, DNSSyncOptions{MaxRemovals: -1})
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Applied, 11)
+		assert.Len(t, *writes, 11)
+	}
+}
+
+func TestAssetsDNSSync_ApplyDNSSyncPlanFailures(t *testing.T) {
+	setup()
+	defer teardown()
+
+	dnsSyncHandlers(t, "/external-dns-name/b.example.com")
+
+	plan := DNSSyncPlan{DeploymentID: testDeploymentId, Steps: []DNSSyncStep{
+		{Action: CreateDNSNameAction, DNSName: "a.example.com"},
+		{Action: CreateDNSNameAction, DNSName: "b.example.com"},
+		{Action: "rename", DNSName: "c.example.com"},
+	}}
+
+	result, err := client.ApplyDNSSyncPlan(plan, DNSSyncOptions{})
+
+	assert.EqualError(t, err, "2 of 3 DNS sync steps failed")
+	assert.Equal(t, []DNSSyncStep{{Action: CreateDNSNameAction, DNSName: "a.example.com"}}, result.Applied)
+	if assert.Len(t, result.Failed, 2) {
+		assert.Contains(t, result.Failed[0].Error(), "+ create external DNS name b.example.com: error from makeRequest")
+		assert.Equal(t, `? rename c.example.com: unknown action "rename"`, result.Failed[1].Error())
+	}
+}
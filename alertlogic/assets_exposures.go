@@ -0,0 +1,289 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Severity is the severity of a vulnerability or exposure.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRanks orders the known severities from least to most severe.
+var severityRanks = map[Severity]int{
+	SeverityInfo:     1,
+	SeverityLow:      2,
+	SeverityMedium:   3,
+	SeverityHigh:     4,
+	SeverityCritical: 5,
+}
+
+// UnmarshalJSON decodes a severity, converting it to lower case.
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var severity string
+	if err := json.Unmarshal(data, &severity); err != nil {
+		return err
+	}
+
+	*s = Severity(strings.ToLower(severity))
+	return nil
+}
+
+// Valid reports whether the severity is one of the known severities.
+func (s Severity) Valid() bool {
+	_, ok := severityRanks[s]
+	return ok
+}
+
+// ExposureFilter restricts the exposures and vulnerabilities that are returned. Each field only
+// returns items matching one of its values, and empty fields do not filter.
+type ExposureFilter struct {
+	Severities    []Severity
+	CVEs          []string
+	AssetKeys     []string
+	DeploymentIDs []string
+}
+
+// validate checks that the filter only uses known severities.
+func (f ExposureFilter) validate() error {
+	for _, severity := range f.Severities {
+		if !severity.Valid() {
+			return errors.Errorf("unknown severity %q", severity)
+		}
+	}
+
+	return nil
+}
+
+// severities returns the filter's severities as strings.
+func (f ExposureFilter) severities() []string {
+	severities := make([]string, len(f.Severities))
+	for i, severity := range f.Severities {
+		severities[i] = string(severity)
+	}
+
+	return severities
+}
+
+// Exposure is a vulnerability that one or more assets in an account are exposed to.
+type Exposure struct {
+	VulnerabilityID string             `json:"vulnerability_id"`
+	Name            string             `json:"name"`
+	Description     string             `json:"description,omitempty"`
+	Severity        Severity           `json:"severity"`
+	CVE             string             `json:"cve,omitempty"`
+	CVSSScore       float64            `json:"cvss_score"`
+	CVSSVector      string             `json:"cvss_vector,omitempty"`
+	CVSSVersion     string             `json:"cvss_version,omitempty"`
+	Categories      []string           `json:"categories,omitempty"`
+	RemediationID   string             `json:"remediation_id,omitempty"`
+	VInstancesCount int64              `json:"vinstances_count"`
+	VInstances      []ExposureInstance `json:"vinstances,omitempty"`
+}
+
+// ExposureInstance is an asset that is exposed to a vulnerability.
+type ExposureInstance struct {
+	Key          string `json:"key"`
+	Type         string `json:"type,omitempty"`
+	Name         string `json:"name,omitempty"`
+	DeploymentID string `json:"deployment_id,omitempty"`
+}
+
+type Exposures struct {
+	Exposures []Exposure `json:"exposures"`
+}
+
+// Vulnerability is an asset of the `vulnerability` type, describing a vulnerability found on
+// another asset.
+type Vulnerability struct {
+	Key             string   `json:"key"`
+	Name            string   `json:"name,omitempty"`
+	VulnerabilityID string   `json:"vulnerability_id,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	Resolution      string   `json:"resolution,omitempty"`
+	Severity        Severity `json:"severity"`
+	CVE             string   `json:"cve,omitempty"`
+	CVSSScore       float64  `json:"cvss_score"`
+	CVSSVector      string   `json:"cvss_vector,omitempty"`
+	CVSSVersion     string   `json:"cvss_version,omitempty"`
+	AssetKey        string   `json:"asset_key,omitempty"`
+	DeploymentID    string   `json:"deployment_id,omitempty"`
+	CreatedOn       int64    `json:"created_on,omitempty"`
+	ModifiedOn      int64    `json:"modified_on,omitempty"`
+}
+
+// SeverityCounts holds a number of vulnerabilities or exposures for each severity.
+type SeverityCounts map[Severity]int64
+
+// Total returns the sum of the counts of every severity.
+func (c SeverityCounts) Total() int64 {
+	var total int64
+	for _, count := range c {
+		total += count
+	}
+
+	return total
+}
+
+// Severities returns the severities that have a non-zero count, most severe first. Unknown
+// severities come last in alphabetical order.
+func (c SeverityCounts) Severities() []Severity {
+	severities := []Severity{}
+	for severity, count := range c {
+		if count != 0 {
+			severities = append(severities, severity)
+		}
+	}
+
+	sort.Slice(severities, func(i, j int) bool {
+		ri, rj := severityRanks[severities[i]], severityRanks[severities[j]]
+		if ri != rj {
+			return ri > rj
+		}
+		return severities[i] < severities[j]
+	})
+
+	return severities
+}
+
+// String returns the counts as `severity=count` pairs, most severe first.
+func (c SeverityCounts) String() string {
+	pairs := []string{}
+	for _, severity := range c.Severities() {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", severity, c[severity]))
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// CountBySeverity counts the exposures of each severity. If `byInstance` is set each exposure
+// counts once for every asset exposed to it, rather than once.
+func (e Exposures) CountBySeverity(byInstance bool) SeverityCounts {
+	counts := SeverityCounts{}
+	for _, exposure := range e.Exposures {
+		if byInstance {
+			counts[exposure.Severity] += exposure.VInstancesCount
+		} else {
+			counts[exposure.Severity]++
+		}
+	}
+
+	return counts
+}
+
+// CountVulnerabilitiesBySeverity counts the vulnerabilities of each severity.
+func CountVulnerabilitiesBySeverity(vulnerabilities []Vulnerability) SeverityCounts {
+	counts := SeverityCounts{}
+	for _, vulnerability := range vulnerabilities {
+		counts[vulnerability.Severity]++
+	}
+
+	return counts
+}
+
+// ListExposures lists the vulnerabilities that assets in an account are exposed to, with the
+// assets exposed to each one.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_query/#api-Exposures-QueryExposures
+func (api *API) ListExposures(filter ExposureFilter) (Exposures, error) {
+	if err := filter.validate(); err != nil {
+		return Exposures{}, err
+	}
+
+	params := map[string]string{}
+	for name, values := range map[string][]string{
+		"severity":      filter.severities(),
+		"cve":           filter.CVEs,
+		"asset_key":     filter.AssetKeys,
+		"deployment_id": filter.DeploymentIDs,
+	} {
+		if len(values) > 0 {
+			params[name] = strings.Join(values, ",")
+		}
+	}
+
+	res, _, err := api.makeRequest("GET", fmt.Sprintf("%s/%s/exposures", assetsQueryServicePath, api.AccountID), nil, params, nil)
+
+	if err != nil {
+		return Exposures{}, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r Exposures
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return Exposures{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// QueryVulnerabilities queries an account's assets of the `vulnerability` type.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_query/#api-Queries-QueryAccountAssets
+func (api *API) QueryVulnerabilities(filter ExposureFilter) ([]Vulnerability, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	query := NewAssetQuery().AssetType("v", AssetTypeVulnerability)
+	if len(filter.Severities) > 0 {
+		query.Filter("v", "severity", filter.severities()...)
+	}
+	if len(filter.CVEs) > 0 {
+		query.Filter("v", "cve", filter.CVEs...)
+	}
+	if len(filter.AssetKeys) > 0 {
+		query.Filter("v", "asset_key", filter.AssetKeys...)
+	}
+	if len(filter.DeploymentIDs) > 0 {
+		query.Filter("v", "deployment_id", filter.DeploymentIDs...)
+	}
+
+	result, err := api.QueryAssets(query)
+	if err != nil {
+		return nil, err
+	}
+
+	vulnerabilities := []Vulnerability{}
+	for _, row := range result.Assets {
+		for _, asset := range row {
+			vulnerability, err := asset.Vulnerability()
+			if err != nil {
+				return nil, err
+			}
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+
+	return vulnerabilities, nil
+}
+
+// Vulnerability converts an asset of the `vulnerability` type to a Vulnerability.
+func (a Asset) Vulnerability() (Vulnerability, error) {
+	if a.Type != AssetTypeVulnerability {
+		return Vulnerability{}, errors.Errorf("asset %s is of type %q, not %q", a.Key, a.Type, AssetTypeVulnerability)
+	}
+
+	data, err := a.MarshalJSON()
+	if err != nil {
+		return Vulnerability{}, err
+	}
+
+	var r Vulnerability
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Vulnerability{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
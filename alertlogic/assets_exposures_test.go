@@ -0,0 +1,134 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	listExposuresPath = fmt.Sprintf("/%s/%s/exposures", assetsQueryServicePath, testAccountId)
+)
+
+const listExposuresReply = `{"exposures": [
+	{"vulnerability_id": "V1", "name": "OpenSSL Heartbleed", "severity": "High", "cve": "CVE-2014-0160",
+	 "cvss_score": 7.5, "cvss_vector": "AV:N/AC:L/Au:N/C:P/I:P/A:P", "cvss_version": "2.0", "vinstances_count": 2,
+	 "vinstances": [
+		{"key": "/aws/us-east-1/host/i-1", "type": "host", "deployment_id": "50668317-feb8-49d1-b401-7219bfa22417"},
+		{"key": "/aws/us-east-1/host/i-2", "type": "host", "deployment_id": "50668317-feb8-49d1-b401-7219bfa22417"}
+	 ]},
+	{"vulnerability_id": "V2", "name": "Weak TLS ciphers", "severity": "medium", "cvss_score": 5.0, "vinstances_count": 1},
+	{"vulnerability_id": "V3", "name": "Log4Shell", "severity": "critical", "cve": "CVE-2021-44228", "cvss_score": 10.0,
+	 "cvss_vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", "cvss_version": "3.1", "vinstances_count": 3}
+]}`
+
+func TestAssetsExposures_ListExposures(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(listExposuresPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "high,critical", r.URL.Query().Get("severity"))
+		assert.Equal(t, "CVE-2014-0160", r.URL.Query().Get("cve"))
+		assert.Equal(t, testDeploymentId, r.URL.Query().Get("deployment_id"))
+		assert.NotContains(t, r.URL.Query(), "asset_key")
+
+		fmt.Fprint(w, listExposuresReply)
+	})
+
+	exposures, err := client.ListExposures(ExposureFilter{
+		Severities:    []Severity{SeverityHigh, SeverityCritical},
+		CVEs:          []string{"CVE-2014-0160"},
+		DeploymentIDs: []string{testDeploymentId},
+	})
+
+	if assert.NoError(t, err) && assert.Len(t, exposures.Exposures, 3) {
+		assert.Equal(t, Exposure{
+			VulnerabilityID: "V1",
+			Name:            "OpenSSL Heartbleed",
+			Severity:        SeverityHigh,
+			CVE:             "CVE-2014-0160",
+			CVSSScore:       7.5,
+			CVSSVector:      "AV:N/AC:L/Au:N/C:P/I:P/A:P",
+			CVSSVersion:     "2.0",
+			VInstancesCount: 2,
+			VInstances: []ExposureInstance{
+				{Key: "/aws/us-east-1/host/i-1", Type: "host", DeploymentID: testDeploymentId},
+				{Key: "/aws/us-east-1/host/i-2", Type: "host", DeploymentID: testDeploymentId},
+			},
+		}, exposures.Exposures[0])
+
+		counts := exposures.CountBySeverity(false)
+		assert.Equal(t, SeverityCounts{SeverityCritical: 1, SeverityHigh: 1, SeverityMedium: 1}, counts)
+		assert.Equal(t, int64(3), counts.Total())
+
+		counts = exposures.CountBySeverity(true)
+		assert.Equal(t, int64(6), counts.Total())
+		assert.Equal(t, "critical=3, high=2, medium=1", counts.String())
+	}
+}
+
+func TestAssetsExposures_ListExposuresInvalidFilter(t *testing.T) {
+	_, err := client.ListExposures(ExposureFilter{Severities: []Severity{"severe"}})
+	assert.EqualError(t, err, `unknown severity "severe"`)
+
+	_, err = client.QueryVulnerabilities(ExposureFilter{Severities: []Severity{"High"}})
+	assert.EqualError(t, err, `unknown severity "High"`)
+}
+
+func TestAssetsExposures_QueryVulnerabilities(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getExternalDNSNameAssetsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "v:vulnerability", r.URL.Query().Get("asset_types"))
+		assert.Equal(t, "high", r.URL.Query().Get("v.severity"))
+		assert.Equal(t, "/aws/us-east-1/host/i-1", r.URL.Query().Get("v.asset_key"))
+
+		fmt.Fprint(w, `{"rows": 2, "assets": [
+			[{"key": "/vulnerability/V1/i-1", "type": "vulnerability", "vulnerability_id": "V1", "name": "OpenSSL Heartbleed",
+			  "severity": "HIGH", "cve": "CVE-2014-0160", "cvss_score": 7.5, "asset_key": "/aws/us-east-1/host/i-1"}],
+			[{"key": "/vulnerability/V4/i-1", "type": "vulnerability", "vulnerability_id": "V4", "severity": "high",
+			  "cvss_score": 8.1, "asset_key": "/aws/us-east-1/host/i-1"}]
+		]}`)
+	})
+
+	vulnerabilities, err := client.QueryVulnerabilities(ExposureFilter{
+		Severities: []Severity{SeverityHigh},
+		AssetKeys:  []string{"/aws/us-east-1/host/i-1"},
+	})
+
+	if assert.NoError(t, err) && assert.Len(t, vulnerabilities, 2) {
+		assert.Equal(t, Vulnerability{
+			Key:             "/vulnerability/V1/i-1",
+			VulnerabilityID: "V1",
+			Name:            "OpenSSL Heartbleed",
+			Severity:        SeverityHigh,
+			CVE:             "CVE-2014-0160",
+			CVSSScore:       7.5,
+			AssetKey:        "/aws/us-east-1/host/i-1",
+		}, vulnerabilities[0])
+		assert.Equal(t, SeverityCounts{SeverityHigh: 2}, CountVulnerabilitiesBySeverity(vulnerabilities))
+	}
+}
+
+func TestAssetsExposures_SeverityCounts(t *testing.T) {
+	counts := SeverityCounts{SeverityLow: 4, SeverityInfo: 0, "unknown": 1, SeverityCritical: 2}
+
+	assert.Equal(t, []Severity{SeverityCritical, SeverityLow, "unknown"}, counts.Severities())
+	assert.Equal(t, "critical=2, low=4, unknown=1", counts.String())
+	assert.Equal(t, int64(7), counts.Total())
+	assert.Equal(t, "", SeverityCounts{}.String())
+
+	var severity Severity
+	assert.Error(t, json.Unmarshal([]byte(`1`), &severity))
+	assert.True(t, SeverityInfo.Valid())
+	assert.False(t, Severity("High").Valid())
+
+	_, err := Asset{Key: "/aws/us-east-1/host/i-1", Type: AssetTypeHost}.Vulnerability()
+	assert.EqualError(t, err, `asset /aws/us-east-1/host/i-1 is of type "host", not "vulnerability"`)
+}
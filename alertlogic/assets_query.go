@@ -3,10 +3,25 @@ package alertlogic
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
+// Asset types that can be queried with QueryAssets.
+const (
+	AssetTypeHost            = "host"
+	AssetTypeVPC             = "vpc"
+	AssetTypeSubnet          = "subnet"
+	AssetTypeSecurityGroup   = "sg"
+	AssetTypeLoadBalancer    = "load-balancer"
+	AssetTypeRegion          = "region"
+	AssetTypeVulnerability   = "vulnerability"
+	AssetTypeExternalDNSName = "external-dns-name"
+	AssetTypeExternalIP      = "external-ip"
+)
+
 type ExternalDNSNameAssets struct {
 	Rows              int64                    `json:"rows"`
 	ExternalDNSAssets [][]ExternalDNSNameAsset `json:"assets"`
@@ -17,8 +32,8 @@ type ExternalDNSNameAsset struct {
 	Type                              string  `json:"type,omitempty"`
 	Threatiness                       float64 `json:"threatiness,omitempty"`
 	ThreatLevel                       int64   `json:"threat_level,omitempty"`
-	Tags                              Tag     `json:"tags,omitempty"`
-	TagKeys                           Tag     `json:"tag_keys,omitempty"`
+	Tags                              Tags    `json:"tags,omitempty"`
+	TagKeys                           TagKeys `json:"tag_keys,omitempty"`
 	State                             string  `json:"state,omitempty"`
 	ScopeExternalScanRequestID        string  `json:"scope_external_scan_request_id,omitempty"`
 	ScopeExternalLastExternalScanTime int64   `json:"scope_external_last_external_scan_time,omitempty"`
@@ -39,24 +54,360 @@ type ExternalDNSNameAsset struct {
 	AccountID                         string  `json:"account_id,omitempty"`
 }
 
-type Tag struct{}
+// Tags holds an asset's tags as a map of tag keys to values. Non-string values returned by the
+// API are converted to strings.
+type Tags map[string]string
 
-// GetExternalDNSNamesAssets gets external DNS assets for an account.
-// This endpoint only gets assets that are of the `external-dns-name` type.
+// TagKeys holds the keys of an asset's tags, in sorted order. The API may return them either as
+// a list or as an object keyed by tag key.
+type TagKeys []string
+
+// UnmarshalJSON decodes tags from an object of tag keys to values or a list of `key` and `value`
+// objects.
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	tags := Tags{}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err == nil {
+		for key, value := range object {
+			tags[key] = tagValue(value)
+		}
+		*t = tags
+		return nil
+	}
+
+	var list []struct {
+		Key   string      `json:"key"`
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.Errorf("invalid tags %s", data)
+	}
+	for _, tag := range list {
+		tags[tag.Key] = tagValue(tag.Value)
+	}
+	*t = tags
+
+	return nil
+}
+
+// UnmarshalJSON decodes tag keys from a list of keys or an object keyed by tag key.
+func (k *TagKeys) UnmarshalJSON(data []byte) error {
+	keys := TagKeys{}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err == nil {
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		*k = keys
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.Errorf("invalid tag keys %s", data)
+	}
+	*k = append(keys, list...)
+
+	return nil
+}
+
+// tagValue converts a decoded tag value to a string.
+func tagValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	}
+
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// AssetQuery builds a query for QueryAssets. Every asset type in a query is given an alias, which
+// filters and return types refer to. The zero value is not usable; create queries with
+// NewAssetQuery.
+type AssetQuery struct {
+	assetTypes          []string
+	aliases             map[string]bool
+	filters             map[string][]string
+	returnTypes         []string
+	scope               string
+	deploymentId        string
+	reduce              bool
+	returnRelationships bool
+}
+
+// AssetQueryResult holds the result of QueryAssets. Each row holds one asset for every asset
+// type returned by the query, in the order the types were added.
+type AssetQueryResult struct {
+	Rows   int64     `json:"rows"`
+	Assets [][]Asset `json:"assets"`
+}
+
+// Asset is an asset of any type. The properties common to all asset types are decoded into
+// fields, and every property, including those, can be read with the typed accessors.
+type Asset struct {
+	Key          string  `json:"key,omitempty"`
+	Type         string  `json:"type,omitempty"`
+	NativeType   string  `json:"native_type,omitempty"`
+	Name         string  `json:"name,omitempty"`
+	State        string  `json:"state,omitempty"`
+	AccountID    string  `json:"account_id,omitempty"`
+	DeploymentID string  `json:"deployment_id,omitempty"`
+	Version      int64   `json:"version,omitempty"`
+	Declared     bool    `json:"declared,omitempty"`
+	Threatiness  float64 `json:"threatiness,omitempty"`
+	ThreatLevel  int64   `json:"threat_level,omitempty"`
+	CreatedOn    int64   `json:"created_on,omitempty"`
+	ModifiedOn   int64   `json:"modified_on,omitempty"`
+	DeletedOn    int64   `json:"deleted_on,omitempty"`
+	Tags         Tags    `json:"tags,omitempty"`
+	TagKeys      TagKeys `json:"tag_keys,omitempty"`
+
+	// Properties holds every property of the asset as returned by the API.
+	Properties map[string]json.RawMessage `json:"-"`
+}
+
+// NewAssetQuery creates an empty asset query.
+func NewAssetQuery() *AssetQuery {
+	return &AssetQuery{
+		aliases: map[string]bool{},
+		filters: map[string][]string{},
+	}
+}
+
+// AssetType adds an asset type to the query with `alias`, for example
+// `AssetType("h", AssetTypeHost)`.
+func (q *AssetQuery) AssetType(alias string, assetType string) *AssetQuery {
+	q.assetTypes = append(q.assetTypes, alias+":"+assetType)
+	q.aliases[alias] = true
+	return q
+}
+
+// Filter only returns assets of the type with `alias` whose `property` is one of `values`.
+func (q *AssetQuery) Filter(alias string, property string, values ...string) *AssetQuery {
+	key := alias + "." + property
+	q.filters[key] = append(q.filters[key], values...)
+	return q
+}
+
+// FilterTag only returns assets of the type with `alias` that have the tag `key` with one of
+// `values`.
+func (q *AssetQuery) FilterTag(alias string, key string, values ...string) *AssetQuery {
+	return q.Filter(alias, "tags."+key, values...)
+}
+
+// ReturnTypes only returns the asset types with the given aliases. By default every asset type
+// in the query is returned.
+func (q *AssetQuery) ReturnTypes(aliases ...string) *AssetQuery {
+	q.returnTypes = append(q.returnTypes, aliases...)
+	return q
+}
+
+// Scope only returns assets in `scope`, for example `aws` or `azure`.
+func (q *AssetQuery) Scope(scope string) *AssetQuery {
+	q.scope = scope
+	return q
+}
+
+// Deployment only returns assets in the deployment with `deploymentId`.
+func (q *AssetQuery) Deployment(deploymentId string) *AssetQuery {
+	q.deploymentId = deploymentId
+	return q
+}
+
+// Reduce removes duplicate rows from the result.
+func (q *AssetQuery) Reduce(reduce bool) *AssetQuery {
+	q.reduce = reduce
+	return q
+}
+
+// ReturnRelationships includes the relationships of each asset in the result.
+func (q *AssetQuery) ReturnRelationships(returnRelationships bool) *AssetQuery {
+	q.returnRelationships = returnRelationships
+	return q
+}
+
+// Params returns the query as request parameters, checking that it has at least one asset type
+// and that filters and return types only use aliases of its asset types.
+func (q *AssetQuery) Params() (map[string]string, error) {
+	if q == nil || len(q.assetTypes) == 0 {
+		return nil, errors.New(errEmptyAssetQuery)
+	}
+
+	params := map[string]string{"asset_types": strings.Join(q.assetTypes, ",")}
+
+	keys := make([]string, 0, len(q.filters))
+	for key := range q.filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		alias := key[:strings.Index(key, ".")]
+		if !q.aliases[alias] {
+			return nil, errors.Errorf("filter %s uses unknown asset type alias %q", key, alias)
+		}
+		params[key] = strings.Join(q.filters[key], ",")
+	}
+
+	for _, alias := range q.returnTypes {
+		if !q.aliases[alias] {
+			return nil, errors.Errorf("return type uses unknown asset type alias %q", alias)
+		}
+	}
+	if len(q.returnTypes) > 0 {
+		params["return_types"] = strings.Join(q.returnTypes, ",")
+	}
+
+	if q.scope != "" {
+		params["scope"] = q.scope
+	}
+	if q.deploymentId != "" {
+		params["deployment_id"] = q.deploymentId
+	}
+	if q.reduce {
+		params["reduce"] = "true"
+	}
+	if q.returnRelationships {
+		params["return_relationships"] = "true"
+	}
+
+	return params, nil
+}
+
+// UnmarshalJSON decodes an asset, keeping every property in `Properties`.
+func (a *Asset) UnmarshalJSON(data []byte) error {
+	type asset Asset
+	if err := json.Unmarshal(data, (*asset)(a)); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &a.Properties)
+}
+
+// MarshalJSON encodes the asset with all of its properties.
+func (a Asset) MarshalJSON() ([]byte, error) {
+	if a.Properties == nil {
+		type asset Asset
+		return json.Marshal(asset(a))
+	}
+
+	return json.Marshal(a.Properties)
+}
+
+// HasProperty reports whether the asset has `property`.
+func (a Asset) HasProperty(property string) bool {
+	_, ok := a.Properties[property]
+	return ok
+}
+
+// Property decodes `property` into `v`. It returns an error if the asset does not have the
+// property or it cannot be decoded into `v`.
+func (a Asset) Property(property string, v interface{}) error {
+	raw, ok := a.Properties[property]
+	if !ok {
+		return errors.Errorf("asset %s has no property %q", a.Key, property)
+	}
+
+	return errors.Wrapf(json.Unmarshal(raw, v), "error decoding property %q of asset %s", property, a.Key)
+}
+
+// StringProperty returns `property` as a string, and whether it exists and is a string.
+func (a Asset) StringProperty(property string) (string, bool) {
+	var v string
+	err := a.Property(property, &v)
+	return v, err == nil
+}
+
+// Int64Property returns `property` as an int64, and whether it exists and is an integer.
+func (a Asset) Int64Property(property string) (int64, bool) {
+	var v int64
+	err := a.Property(property, &v)
+	return v, err == nil
+}
+
+// Float64Property returns `property` as a float64, and whether it exists and is a number.
+func (a Asset) Float64Property(property string) (float64, bool) {
+	var v float64
+	err := a.Property(property, &v)
+	return v, err == nil
+}
+
+// BoolProperty returns `property` as a bool, and whether it exists and is a boolean.
+func (a Asset) BoolProperty(property string) (bool, bool) {
+	var v bool
+	err := a.Property(property, &v)
+	return v, err == nil
+}
+
+// ExternalDNSNameAsset converts an asset of the `external-dns-name` type to an
+// ExternalDNSNameAsset.
+func (a Asset) ExternalDNSNameAsset() (ExternalDNSNameAsset, error) {
+	if a.Type != AssetTypeExternalDNSName {
+		return ExternalDNSNameAsset{}, errors.Errorf("asset %s is of type %q, not %q", a.Key, a.Type, AssetTypeExternalDNSName)
+	}
+
+	data, err := a.MarshalJSON()
+	if err != nil {
+		return ExternalDNSNameAsset{}, err
+	}
+
+	var r ExternalDNSNameAsset
+	if err := json.Unmarshal(data, &r); err != nil {
+		return ExternalDNSNameAsset{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// QueryAssets queries an account's assets of any type.
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/assets_query/#api-Queries-QueryAccountAssets
-func (api *API) GetExternalDNSNameAssets() (ExternalDNSNameAssets, error) {
-	params := map[string]string{"asset_types": "e:external-dns-name"}
+func (api *API) QueryAssets(query *AssetQuery) (AssetQueryResult, error) {
+	params, err := query.Params()
+	if err != nil {
+		return AssetQueryResult{}, err
+	}
+
 	res, _, err := api.makeRequest("GET", fmt.Sprintf("%s/%s/assets", assetsQueryServicePath, api.AccountID), nil, params, nil)
 
 	if err != nil {
-		return ExternalDNSNameAssets{}, errors.Wrap(err, errMakeRequestError)
+		return AssetQueryResult{}, errors.Wrap(err, errMakeRequestError)
 	}
 
-	var r ExternalDNSNameAssets
+	var r AssetQueryResult
 	err = json.Unmarshal(res, &r)
 	if err != nil {
-		return ExternalDNSNameAssets{}, errors.Wrap(err, errUnmarshalError)
+		return AssetQueryResult{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// GetExternalDNSNamesAssets gets external DNS assets for an account.
+// This endpoint only gets assets that are of the `external-dns-name` type. Use QueryAssets to
+// query assets of other types.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_query/#api-Queries-QueryAccountAssets
+func (api *API) GetExternalDNSNameAssets() (ExternalDNSNameAssets, error) {
+	result, err := api.QueryAssets(NewAssetQuery().AssetType("e", AssetTypeExternalDNSName))
+	if err != nil {
+		return ExternalDNSNameAssets{}, err
+	}
+
+	r := ExternalDNSNameAssets{Rows: result.Rows, ExternalDNSAssets: make([][]ExternalDNSNameAsset, len(result.Assets))}
+	for i, row := range result.Assets {
+		r.ExternalDNSAssets[i] = make([]ExternalDNSNameAsset, len(row))
+		for j, asset := range row {
+			if r.ExternalDNSAssets[i][j], err = asset.ExternalDNSNameAsset(); err != nil {
+				return ExternalDNSNameAssets{}, err
+			}
+		}
 	}
 
 	return r, nil
@@ -1,6 +1,7 @@
 package alertlogic
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -94,8 +95,8 @@ func TestAssetsQuery_GetExternalDNSNameAssets(t *testing.T) {
 					Type:                              "external-dns-name",
 					Threatiness:                       89.78169999999997,
 					ThreatLevel:                       3,
-					Tags:                              Tag{},
-					TagKeys:                           Tag{},
+					Tags:                              Tags{},
+					TagKeys:                           TagKeys{},
 					State:                             "new",
 					ScopeExternalScanRequestID:        "deebd20e-9814-4256-9738-7cfa757c94ae",
 					ScopeExternalLastExternalScanTime: 1631034658,
@@ -122,8 +123,8 @@ func TestAssetsQuery_GetExternalDNSNameAssets(t *testing.T) {
 					Type:                              "external-dns-name",
 					Threatiness:                       93.13829999999996,
 					ThreatLevel:                       3,
-					Tags:                              Tag{},
-					TagKeys:                           Tag{},
+					Tags:                              Tags{},
+					TagKeys:                           TagKeys{},
 					State:                             "new",
 					ScopeExternalScanRequestID:        "d0b4844d-47f6-4305-9723-c49c8e836252",
 					ScopeExternalLastExternalScanTime: 1630996539,
@@ -152,3 +153,166 @@ func TestAssetsQuery_GetExternalDNSNameAssets(t *testing.T) {
 		assert.Equal(t, assets, want)
 	}
 }
+
+func TestAssetsQuery_AssetQueryParams(t *testing.T) {
+	params, err := NewAssetQuery().
+		AssetType("h", AssetTypeHost).
+		AssetType("v", AssetTypeVPC).
+		Filter("h", "state", "running", "stopped").
+		Filter("v", "key", "/aws/us-east-1/vpc/vpc-1").
+		ReturnTypes("h").
+		Scope("aws").
+		Deployment(testDeploymentId).
+		Reduce(true).
+		ReturnRelationships(true).
+		Params()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{
+			"asset_types":          "h:host,v:vpc",
+			"h.state":              "running,stopped",
+			"v.key":                "/aws/us-east-1/vpc/vpc-1",
+			"return_types":         "h",
+			"scope":                "aws",
+			"deployment_id":        testDeploymentId,
+			"reduce":               "true",
+			"return_relationships": "true",
+		}, params)
+	}
+}
+
+func TestAssetsQuery_AssetQueryParamsErrors(t *testing.T) {
+	var queryTests = []struct {
+		query *AssetQuery
+		err   string
+	}{
+		{NewAssetQuery(), errEmptyAssetQuery},
+		{nil, errEmptyAssetQuery},
+		{NewAssetQuery().AssetType("h", AssetTypeHost).Filter("s", "name", "web"), `filter s.name uses unknown asset type alias "s"`},
+		{NewAssetQuery().AssetType("h", AssetTypeHost).ReturnTypes("v"), `return type uses unknown asset type alias "v"`},
+	}
+
+	for _, tt := range queryTests {
+		_, err := tt.query.Params()
+
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestAssetsQuery_QueryAssets(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getExternalDNSNameAssetsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, "h:host,s:subnet", r.URL.Query().Get("asset_types"))
+		assert.Equal(t, "running", r.URL.Query().Get("h.state"))
+
+		fmt.Fprint(w, `{"rows": 1, "assets": [[
+			{"key": "/aws/us-east-1/host/i-1", "type": "host", "name": "web", "state": "running", "threat_level": 2,
+			 "instance_type": "t3.micro", "cores": 2, "cvss_score": 7.5, "public": true},
+			{"key": "/aws/us-east-1/subnet/subnet-1", "type": "subnet", "cidr_block": "10.0.0.0/24"}
+		]]}`)
+	})
+
+	result, err := client.QueryAssets(NewAssetQuery().AssetType("h", AssetTypeHost).AssetType("s", AssetTypeSubnet).Filter("h", "state", "running"))
+
+	if assert.NoError(t, err) && assert.Len(t, result.Assets, 1) && assert.Len(t, result.Assets[0], 2) {
+		host := result.Assets[0][0]
+		assert.Equal(t, "/aws/us-east-1/host/i-1", host.Key)
+		assert.Equal(t, AssetTypeHost, host.Type)
+		assert.Equal(t, int64(2), host.ThreatLevel)
+
+		instanceType, ok := host.StringProperty("instance_type")
+		assert.True(t, ok)
+		assert.Equal(t, "t3.micro", instanceType)
+
+		cores, ok := host.Int64Property("cores")
+		assert.True(t, ok)
+		assert.Equal(t, int64(2), cores)
+
+		score, ok := host.Float64Property("cvss_score")
+		assert.True(t, ok)
+		assert.Equal(t, 7.5, score)
+
+		public, ok := host.BoolProperty("public")
+		assert.True(t, ok)
+		assert.True(t, public)
+
+		_, ok = host.StringProperty("cores")
+		assert.False(t, ok)
+		assert.False(t, host.HasProperty("cidr_block"))
+		assert.EqualError(t, host.Property("cidr_block", new(string)), `asset /aws/us-east-1/host/i-1 has no property "cidr_block"`)
+
+		cidr, _ := result.Assets[0][1].StringProperty("cidr_block")
+		assert.Equal(t, "10.0.0.0/24", cidr)
+
+		_, err = host.ExternalDNSNameAsset()
+		assert.EqualError(t, err, `asset /aws/us-east-1/host/i-1 is of type "host", not "external-dns-name"`)
+	}
+}
+
+func TestAssetsQuery_QueryAssetsInvalidQuery(t *testing.T) {
+	_, err := client.QueryAssets(NewAssetQuery())
+
+	assert.EqualError(t, err, errEmptyAssetQuery)
+}
+
+func TestAssetsQuery_AssetMarshalJSON(t *testing.T) {
+	var asset Asset
+	err := json.Unmarshal([]byte(`{"key": "/external-dns-name/example.com", "type": "external-dns-name", "dns_name": "example.com"}`), &asset)
+
+	if assert.NoError(t, err) {
+		data, err := json.Marshal(asset)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"key": "/external-dns-name/example.com", "type": "external-dns-name", "dns_name": "example.com"}`, string(data))
+
+		dnsAsset, err := asset.ExternalDNSNameAsset()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "example.com", dnsAsset.DNSName)
+		}
+	}
+
+	data, err := json.Marshal(Asset{Key: "/host/h-1", Type: AssetTypeHost})
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"key": "/host/h-1", "type": "host"}`, string(data))
+	}
+}
+
+func TestAssetsQuery_Tags(t *testing.T) {
+	var tagTests = []struct {
+		json    string
+		tags    Tags
+		tagKeys TagKeys
+	}{
+		{`{"tags": {"env": "prod", "tier": 1, "owner": null}, "tag_keys": {"env": true, "tier": true, "owner": true}}`, Tags{"env": "prod", "tier": "1", "owner": ""}, TagKeys{"env", "owner", "tier"}},
+		{`{"tags": [{"key": "env", "value": "prod"}], "tag_keys": ["env"]}`, Tags{"env": "prod"}, TagKeys{"env"}},
+		{`{"tags": {}, "tag_keys": []}`, Tags{}, TagKeys{}},
+		{`{}`, nil, nil},
+	}
+
+	for _, tt := range tagTests {
+		var asset ExternalDNSNameAsset
+		err := json.Unmarshal([]byte(tt.json), &asset)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.tags, asset.Tags)
+			assert.Equal(t, tt.tagKeys, asset.TagKeys)
+		}
+	}
+
+	var asset Asset
+	assert.Error(t, json.Unmarshal([]byte(`{"tags": "env"}`), &asset))
+	assert.Error(t, json.Unmarshal([]byte(`{"tag_keys": 1}`), &asset))
+}
+
+func TestAssetsQuery_FilterTag(t *testing.T) {
+	params, err := NewAssetQuery().AssetType("e", AssetTypeExternalDNSName).FilterTag("e", "env", "prod", "staging").Params()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod,staging", params["e.tags.env"])
+	}
+
+	_, err = NewAssetQuery().AssetType("e", AssetTypeExternalDNSName).FilterTag("h", "env", "prod").Params()
+	assert.EqualError(t, err, `filter h.tags.env uses unknown asset type alias "h"`)
+}
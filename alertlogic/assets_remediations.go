@@ -0,0 +1,208 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RemediationState is the state of a remediation item.
+type RemediationState string
+
+const (
+	RemediationOpen      RemediationState = "open"
+	RemediationConcluded RemediationState = "concluded"
+	RemediationDisposed  RemediationState = "disposed"
+	RemediationSnoozed   RemediationState = "snoozed"
+)
+
+// DisposeReason is the reason a remediation item is disposed of rather than fixed.
+type DisposeReason string
+
+const (
+	DisposeAcceptableRisk      DisposeReason = "acceptable_risk"
+	DisposeCompensatingControl DisposeReason = "compensating_control"
+	DisposeFalsePositive       DisposeReason = "false_positive"
+	DisposeNotApplicable       DisposeReason = "not_applicable"
+)
+
+// Remediation is a remediation item: a fix that addresses one or more vulnerabilities in a
+// deployment. `Expires` is the Unix time at which a disposal or snooze ends, or zero.
+type Remediation struct {
+	ID               string           `json:"remediation_id"`
+	Key              string           `json:"key,omitempty"`
+	Name             string           `json:"name"`
+	Description      string           `json:"description,omitempty"`
+	State            RemediationState `json:"state"`
+	DeploymentID     string           `json:"deployment_id,omitempty"`
+	ThreatLevel      int64            `json:"threat_level,omitempty"`
+	VulnerabilityIDs []string         `json:"vulnerability_ids,omitempty"`
+	VInstancesCount  int64            `json:"vinstances_count,omitempty"`
+	DisposeReason    DisposeReason    `json:"reason,omitempty"`
+	Comment          string           `json:"comment,omitempty"`
+	Expires          int64            `json:"expires,omitempty"`
+	ModifiedOn       int64            `json:"modified_on,omitempty"`
+	ModifiedBy       string           `json:"modified_by,omitempty"`
+}
+
+type Remediations struct {
+	Remediations []Remediation `json:"remediations"`
+}
+
+// RemediationFilter restricts the remediation items returned by ListRemediations. Each field only
+// returns items matching one of its values, and empty fields do not filter.
+type RemediationFilter struct {
+	DeploymentIDs []string
+	States        []RemediationState
+}
+
+// DisposeRemediationOptions describe why remediation items are disposed of. `Reason` is
+// required. If `Expires` is set the items are reopened at that time.
+type DisposeRemediationOptions struct {
+	Reason  DisposeReason
+	Comment string
+	Expires time.Time
+}
+
+// RemediationRequest is the body of a request that changes the state of remediation items.
+type RemediationRequest struct {
+	Operation      string        `json:"operation"`
+	RemediationIDs []string      `json:"remediation_ids"`
+	Reason         DisposeReason `json:"reason,omitempty"`
+	Comment        string        `json:"comment,omitempty"`
+	Expires        int64         `json:"expires,omitempty"`
+}
+
+// ListRemediations lists an account's remediation items.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_query/#api-Remediations-ListRemediations
+func (api *API) ListRemediations(filter RemediationFilter) (Remediations, error) {
+	params := map[string]string{}
+	if len(filter.DeploymentIDs) > 0 {
+		params["deployment_id"] = strings.Join(filter.DeploymentIDs, ",")
+	}
+	if len(filter.States) > 0 {
+		states := make([]string, len(filter.States))
+		for i, state := range filter.States {
+			states[i] = string(state)
+		}
+		params["state"] = strings.Join(states, ",")
+	}
+
+	res, _, err := api.makeRequest("GET", fmt.Sprintf("%s/%s/remediations", assetsQueryServicePath, api.AccountID), nil, params, nil)
+
+	if err != nil {
+		return Remediations{}, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r Remediations
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return Remediations{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return r, nil
+}
+
+// ConcludeRemediation marks remediation items as done. Concluded items are reopened if the
+// vulnerabilities they address are found again.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-Remediations-ConcludeRemediations
+func (api *API) ConcludeRemediation(deploymentId string, remediationIds ...string) (int, error) {
+	return api.modifyRemediations(deploymentId, RemediationRequest{Operation: "conclude_remediations", RemediationIDs: remediationIds})
+}
+
+// UndoConcludeRemediation reopens concluded remediation items.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-Remediations-UndoConcludeRemediations
+func (api *API) UndoConcludeRemediation(deploymentId string, remediationIds ...string) (int, error) {
+	return api.modifyRemediations(deploymentId, RemediationRequest{Operation: "undo_conclude_remediations", RemediationIDs: remediationIds})
+}
+
+// DisposeRemediation disposes of remediation items that will not be fixed, for example because
+// the risk is acceptable.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-Remediations-DisposeRemediations
+func (api *API) DisposeRemediation(deploymentId string, options DisposeRemediationOptions, remediationIds ...string) (int, error) {
+	if options.Reason == "" {
+		return 0, errors.New(errEmptyDisposeReason)
+	}
+
+	expires, err := remediationExpiry(options.Expires)
+	if err != nil {
+		return 0, err
+	}
+
+	return api.modifyRemediations(deploymentId, RemediationRequest{
+		Operation:      "dispose_remediations",
+		RemediationIDs: remediationIds,
+		Reason:         options.Reason,
+		Comment:        options.Comment,
+		Expires:        expires,
+	})
+}
+
+// UndoDisposeRemediation reopens disposed remediation items.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-Remediations-UndoDisposeRemediations
+func (api *API) UndoDisposeRemediation(deploymentId string, remediationIds ...string) (int, error) {
+	return api.modifyRemediations(deploymentId, RemediationRequest{Operation: "undo_dispose_remediations", RemediationIDs: remediationIds})
+}
+
+// SnoozeRemediation hides remediation items until `until`, when they are reopened.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-Remediations-SnoozeRemediations
+func (api *API) SnoozeRemediation(deploymentId string, until time.Time, remediationIds ...string) (int, error) {
+	if until.IsZero() {
+		return 0, errors.New(errEmptySnoozeExpiry)
+	}
+
+	expires, err := remediationExpiry(until)
+	if err != nil {
+		return 0, err
+	}
+
+	return api.modifyRemediations(deploymentId, RemediationRequest{
+		Operation:      "snooze_remediations",
+		RemediationIDs: remediationIds,
+		Expires:        expires,
+	})
+}
+
+// UndoSnoozeRemediation reopens snoozed remediation items before their snooze ends.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-Remediations-UndoSnoozeRemediations
+func (api *API) UndoSnoozeRemediation(deploymentId string, remediationIds ...string) (int, error) {
+	return api.modifyRemediations(deploymentId, RemediationRequest{Operation: "undo_snooze_remediations", RemediationIDs: remediationIds})
+}
+
+// modifyRemediations holds shared logic for changing the state of remediation items.
+func (api *API) modifyRemediations(deploymentId string, request RemediationRequest) (int, error) {
+	if len(request.RemediationIDs) == 0 {
+		return 0, errors.New(errEmptyRemediationIds)
+	}
+
+	_, statusCode, err := api.makeRequest("PUT", fmt.Sprintf("%s/%s/deployments/%s/remediations", assetsWriteServicePath, api.AccountID, deploymentId), nil, nil, request)
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
+// remediationExpiry converts an expiry time to Unix time, returning zero for the zero time and an
+// error for times that are not in the future.
+func remediationExpiry(expires time.Time) (int64, error) {
+	if expires.IsZero() {
+		return 0, nil
+	}
+	if !expires.After(timeNow()) {
+		return 0, errors.Errorf("expiry %s is not in the future", expires.UTC().Format(time.RFC3339))
+	}
+
+	return expires.Unix(), nil
+}
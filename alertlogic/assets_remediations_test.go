@@ -0,0 +1,151 @@
+package alertlogic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	listRemediationsPath  = fmt.Sprintf("/%s/%s/remediations", assetsQueryServicePath, testAccountId)
+	modifyRemediationPath = fmt.Sprintf("/%s/%s/deployments/%s/remediations", assetsWriteServicePath, testAccountId, testDeploymentId)
+)
+
+const testRemediationNow = 1640995200
+
+func TestAssetsRemediations_ListRemediations(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(listRemediationsPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		assert.Equal(t, testDeploymentId, r.URL.Query().Get("deployment_id"))
+		assert.Equal(t, "open,snoozed", r.URL.Query().Get("state"))
+
+		fmt.Fprint(w, `{"remediations": [
+			{"remediation_id": "R1", "key": "/remediation-item/R1", "name": "Update OpenSSL", "state": "open",
+			 "deployment_id": "50668317-feb8-49d1-b401-7219bfa22417", "threat_level": 3, "vulnerability_ids": ["V1"], "vinstances_count": 2},
+			{"remediation_id": "R2", "name": "Disable weak TLS ciphers", "state": "snoozed", "expires": 1641081600, "modified_by": "12345678"}
+		]}`)
+	})
+
+	remediations, err := client.ListRemediations(RemediationFilter{
+		DeploymentIDs: []string{testDeploymentId},
+		States:        []RemediationState{RemediationOpen, RemediationSnoozed},
+	})
+
+	if assert.NoError(t, err) && assert.Len(t, remediations.Remediations, 2) {
+		assert.Equal(t, Remediation{
+			ID:               "R1",
+			Key:              "/remediation-item/R1",
+			Name:             "Update OpenSSL",
+			State:            RemediationOpen,
+			DeploymentID:     testDeploymentId,
+			ThreatLevel:      3,
+			VulnerabilityIDs: []string{"V1"},
+			VInstancesCount:  2,
+		}, remediations.Remediations[0])
+		assert.Equal(t, RemediationSnoozed, remediations.Remediations[1].State)
+		assert.Equal(t, int64(1641081600), remediations.Remediations[1].Expires)
+	}
+}
+
+func TestAssetsRemediations_ModifyRemediations(t *testing.T) {
+	setup()
+	defer teardown()
+
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(testRemediationNow, 0) }
+
+	expires := time.Unix(testRemediationNow, 0).Add(24 * time.Hour)
+
+	var remediationTests = []struct {
+		call func() (int, error)
+		body string
+	}{
+		{
+			func() (int, error) { return client.ConcludeRemediation(testDeploymentId, "R1", "R2") },
+			`{"operation": "conclude_remediations", "remediation_ids": ["R1", "R2"]}`,
+		},
+		{
+			func() (int, error) { return client.UndoConcludeRemediation(testDeploymentId, "R1") },
+			`{"operation": "undo_conclude_remediations", "remediation_ids": ["R1"]}`,
+		},
+		{
+			func() (int, error) {
+				return client.DisposeRemediation(testDeploymentId, DisposeRemediationOptions{Reason: DisposeAcceptableRisk, Comment: "Internal only", Expires: expires}, "R1")
+			},
+			`{"operation": "dispose_remediations", "remediation_ids": ["R1"], "reason": "acceptable_risk", "comment": "Internal only", "expires": 1641081600}`,
+		},
+		{
+			func() (int, error) {
+				return client.DisposeRemediation(testDeploymentId, DisposeRemediationOptions{Reason: DisposeFalsePositive}, "R1")
+			},
+			`{"operation": "dispose_remediations", "remediation_ids": ["R1"], "reason": "false_positive"}`,
+		},
+		{
+			func() (int, error) { return client.UndoDisposeRemediation(testDeploymentId, "R1") },
+			`{"operation": "undo_dispose_remediations", "remediation_ids": ["R1"]}`,
+		},
+		{
+			func() (int, error) { return client.SnoozeRemediation(testDeploymentId, expires, "R2") },
+			`{"operation": "snooze_remediations", "remediation_ids": ["R2"], "expires": 1641081600}`,
+		},
+		{
+			func() (int, error) { return client.UndoSnoozeRemediation(testDeploymentId, "R2") },
+			`{"operation": "undo_snooze_remediations", "remediation_ids": ["R2"]}`,
+		},
+	}
+
+	var body string
+	mux.HandleFunc(modifyRemediationPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for _, tt := range remediationTests {
+		status, err := tt.call()
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusNoContent, status)
+			assert.JSONEq(t, tt.body, body)
+		}
+	}
+}
+
+func TestAssetsRemediations_ModifyRemediationsErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(testRemediationNow, 0) }
+
+	_, err := client.ConcludeRemediation(testDeploymentId)
+	assert.EqualError(t, err, errEmptyRemediationIds)
+
+	_, err = client.DisposeRemediation(testDeploymentId, DisposeRemediationOptions{}, "R1")
+	assert.EqualError(t, err, errEmptyDisposeReason)
+
+	_, err = client.DisposeRemediation(testDeploymentId, DisposeRemediationOptions{Reason: DisposeAcceptableRisk, Expires: time.Unix(testRemediationNow, 0)}, "R1")
+	assert.EqualError(t, err, "expiry 2022-01-01T00:00:00Z is not in the future")
+
+	_, err = client.SnoozeRemediation(testDeploymentId, time.Time{}, "R1")
+	assert.EqualError(t, err, errEmptySnoozeExpiry)
+
+	mux.HandleFunc(modifyRemediationPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "remediation not found"}`)
+	})
+
+	status, err := client.UndoSnoozeRemediation(testDeploymentId, "R3")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, http.StatusNotFound, StatusCode(err))
+}
@@ -0,0 +1,283 @@
+package alertlogic
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AssetGraph is an in-memory graph of a deployment's assets, for example region → VPC →
+// subnet → host. An asset may have more than one parent, such as a security group that belongs
+// to a VPC and is attached to hosts. Assets are identified by their key.
+type AssetGraph struct {
+	DeploymentID string
+
+	assets   map[string]Asset
+	children map[string][]string
+	parents  map[string][]string
+	roots    []string
+}
+
+// topologyNode is an asset in a topology response together with its children.
+type topologyNode struct {
+	Asset    Asset
+	Children []topologyNode
+}
+
+// UnmarshalJSON decodes a topology node, leaving `children` out of the asset's properties.
+func (n *topologyNode) UnmarshalJSON(data []byte) error {
+	var node struct {
+		Children []topologyNode `json:"children"`
+	}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &n.Asset); err != nil {
+		return err
+	}
+
+	delete(n.Asset.Properties, "children")
+	n.Children = node.Children
+
+	return nil
+}
+
+// GetTopology gets the topology of a deployment's assets and builds an AssetGraph from it.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_query/#api-Topology-GetTopology
+func (api *API) GetTopology(deploymentId string) (*AssetGraph, error) {
+	res, _, err := api.makeRequest("GET", fmt.Sprintf("%s/%s/deployments/%s/topology", assetsQueryServicePath, api.AccountID, deploymentId), nil, nil, nil)
+
+	if err != nil {
+		return nil, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var r struct {
+		Topology topologyNode `json:"topology"`
+	}
+	err = json.Unmarshal(res, &r)
+	if err != nil {
+		return nil, errors.Wrap(err, errUnmarshalError)
+	}
+
+	graph := newAssetGraph(deploymentId)
+	graph.addNode(r.Topology, "")
+
+	return graph, nil
+}
+
+// newAssetGraph creates an empty asset graph for a deployment.
+func newAssetGraph(deploymentId string) *AssetGraph {
+	return &AssetGraph{
+		DeploymentID: deploymentId,
+		assets:       map[string]Asset{},
+		children:     map[string][]string{},
+		parents:      map[string][]string{},
+	}
+}
+
+// addNode adds a topology node and its children to the graph below the asset with key `parent`,
+// or as a root if `parent` is empty. Nodes without a key only group their children.
+func (g *AssetGraph) addNode(node topologyNode, parent string) {
+	key := node.Asset.Key
+	if key != "" {
+		if _, exists := g.assets[key]; !exists {
+			g.assets[key] = node.Asset
+		}
+		if parent == "" {
+			g.addRoot(key)
+		} else {
+			g.addEdge(parent, key)
+		}
+		parent = key
+	}
+
+	for _, child := range node.Children {
+		g.addNode(child, parent)
+	}
+}
+
+// addRoot records `key` as a root of the graph.
+func (g *AssetGraph) addRoot(key string) {
+	for _, root := range g.roots {
+		if root == key {
+			return
+		}
+	}
+	g.roots = append(g.roots, key)
+}
+
+// addEdge records `child` as a child of `parent`, ignoring duplicate edges.
+func (g *AssetGraph) addEdge(parent string, child string) {
+	for _, existing := range g.children[parent] {
+		if existing == child {
+			return
+		}
+	}
+	g.children[parent] = append(g.children[parent], child)
+	g.parents[child] = append(g.parents[child], parent)
+}
+
+// Len returns the number of assets in the graph.
+func (g *AssetGraph) Len() int {
+	return len(g.assets)
+}
+
+// Asset returns the asset with `key`, and whether it is in the graph.
+func (g *AssetGraph) Asset(key string) (Asset, bool) {
+	asset, ok := g.assets[key]
+	return asset, ok
+}
+
+// Assets returns every asset in the graph, sorted by key.
+func (g *AssetGraph) Assets() []Asset {
+	keys := map[string]bool{}
+	for key := range g.assets {
+		keys[key] = true
+	}
+
+	return g.lookup(sortedKeys(keys))
+}
+
+// AssetsOfType returns the assets of `assetType` in the graph, sorted by key.
+func (g *AssetGraph) AssetsOfType(assetType string) []Asset {
+	assets := []Asset{}
+	for _, asset := range g.Assets() {
+		if asset.Type == assetType {
+			assets = append(assets, asset)
+		}
+	}
+
+	return assets
+}
+
+// Roots returns the assets that have no parent, in the order they were returned by the API.
+func (g *AssetGraph) Roots() []Asset {
+	return g.lookup(g.roots)
+}
+
+// Children returns the direct children of the asset with `key`.
+func (g *AssetGraph) Children(key string) []Asset {
+	return g.lookup(g.children[key])
+}
+
+// Parents returns the direct parents of the asset with `key`.
+func (g *AssetGraph) Parents(key string) []Asset {
+	return g.lookup(g.parents[key])
+}
+
+// Ancestors returns every asset above the asset with `key`, nearest first. For a host this is
+// typically its subnet, VPC and region.
+func (g *AssetGraph) Ancestors(key string) []Asset {
+	return g.lookup(g.walk(key, g.parents))
+}
+
+// Descendants returns every asset below the asset with `key`, nearest first.
+func (g *AssetGraph) Descendants(key string) []Asset {
+	return g.lookup(g.walk(key, g.children))
+}
+
+// PathTo returns the shortest path from a root of the graph to the asset with `key`, starting
+// with the root and ending with the asset. It returns nil if the asset is not in the graph.
+func (g *AssetGraph) PathTo(key string) []Asset {
+	if _, ok := g.assets[key]; !ok {
+		return nil
+	}
+
+	next := map[string]string{}
+	queue := []string{key}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if len(g.parents[current]) == 0 {
+			path := []string{current}
+			for path[len(path)-1] != key {
+				path = append(path, next[path[len(path)-1]])
+			}
+			return g.lookup(path)
+		}
+
+		for _, parent := range g.parents[current] {
+			if _, seen := next[parent]; seen || parent == key {
+				continue
+			}
+			next[parent] = current
+			queue = append(queue, parent)
+		}
+	}
+
+	// Every ancestor is part of a cycle, so there is no root above the asset.
+	return nil
+}
+
+// walk returns the keys reachable from `key` through `edges` in breadth-first order, not
+// including `key` itself.
+func (g *AssetGraph) walk(key string, edges map[string][]string) []string {
+	keys := []string{}
+	seen := map[string]bool{key: true}
+
+	queue := []string{key}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range edges[current] {
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			keys = append(keys, next)
+			queue = append(queue, next)
+		}
+	}
+
+	return keys
+}
+
+// lookup returns the assets with `keys`.
+func (g *AssetGraph) lookup(keys []string) []Asset {
+	assets := make([]Asset, len(keys))
+	for i, key := range keys {
+		assets[i] = g.assets[key]
+	}
+
+	return assets
+}
+
+// WriteDOT writes the graph to `w` in the Graphviz DOT language. Each asset is labelled with its
+// type and name, or its key if it has no name.
+func (g *AssetGraph) WriteDOT(w io.Writer) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "digraph %s {\n", dotQuote(g.DeploymentID))
+	fmt.Fprintln(b, "  rankdir=LR;")
+
+	for _, asset := range g.Assets() {
+		label := asset.Name
+		if label == "" {
+			label = asset.Key
+		}
+		fmt.Fprintf(b, "  %s [label=%s];\n", dotQuote(asset.Key), dotQuote(asset.Type+"\n"+label))
+	}
+
+	for _, asset := range g.Assets() {
+		for _, child := range g.children[asset.Key] {
+			fmt.Fprintf(b, "  %s -> %s;\n", dotQuote(asset.Key), dotQuote(child))
+		}
+	}
+
+	fmt.Fprintln(b, "}")
+
+	return errors.Wrap(b.Flush(), "error writing DOT graph")
+}
+
+// dotQuote quotes `s` as a DOT identifier.
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
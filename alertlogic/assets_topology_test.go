@@ -0,0 +1,113 @@
+package alertlogic
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	getTopologyPath = fmt.Sprintf("/%s/%s/deployments/%s/topology", assetsQueryServicePath, testAccountId, testDeploymentId)
+)
+
+const (
+	topologyRegionKey = "/aws/us-east-1"
+	topologyVPCKey    = "/aws/us-east-1/vpc/vpc-1"
+	topologySubnetKey = "/aws/us-east-1/subnet/subnet-1"
+	topologyHostKey   = "/aws/us-east-1/host/i-1"
+	topologySGKey     = "/aws/us-east-1/sg/sg-1"
+	topologyLBKey     = "/aws/us-east-1/load-balancer/lb-1"
+	topologyDNSKey    = "/external-dns-name/lb-1.example.com"
+)
+
+const getTopologyReply = `{"topology": {"children": [
+	{"key": "/aws/us-east-1", "type": "region", "name": "us-east-1", "children": [
+		{"key": "/aws/us-east-1/vpc/vpc-1", "type": "vpc", "name": "main", "cidr_block": "10.0.0.0/16", "children": [
+			{"key": "/aws/us-east-1/subnet/subnet-1", "type": "subnet", "children": [
+				{"key": "/aws/us-east-1/host/i-1", "type": "host", "name": "web"}
+			]},
+			{"key": "/aws/us-east-1/sg/sg-1", "type": "sg", "name": "web \"public\"", "children": [
+				{"key": "/aws/us-east-1/host/i-1", "type": "host", "name": "web"}
+			]},
+			{"key": "/aws/us-east-1/load-balancer/lb-1", "type": "load-balancer", "name": "lb", "children": [
+				{"key": "/aws/us-east-1/host/i-1", "type": "host", "name": "web"},
+				{"key": "/external-dns-name/lb-1.example.com", "type": "external-dns-name", "dns_name": "lb-1.example.com"}
+			]}
+		]}
+	]}
+]}}`
+
+// assetKeys returns the keys of `assets`.
+func assetKeys(assets []Asset) []string {
+	keys := make([]string, len(assets))
+	for i, asset := range assets {
+		keys[i] = asset.Key
+	}
+
+	return keys
+}
+
+func TestAssetsTopology_GetTopology(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getTopologyPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		fmt.Fprint(w, getTopologyReply)
+	})
+
+	graph, err := client.GetTopology(testDeploymentId)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, testDeploymentId, graph.DeploymentID)
+		assert.Equal(t, 7, graph.Len())
+		assert.Equal(t, []string{topologyRegionKey}, assetKeys(graph.Roots()))
+		assert.Equal(t, []string{topologySubnetKey, topologySGKey, topologyLBKey}, assetKeys(graph.Children(topologyVPCKey)))
+		assert.Equal(t, []string{topologySubnetKey, topologySGKey, topologyLBKey}, assetKeys(graph.Parents(topologyHostKey)))
+		assert.Equal(t, []string{topologySubnetKey, topologySGKey, topologyLBKey, topologyVPCKey, topologyRegionKey}, assetKeys(graph.Ancestors(topologyHostKey)))
+		assert.Equal(t, []string{topologyHostKey, topologyDNSKey}, assetKeys(graph.Descendants(topologyLBKey)))
+		assert.Equal(t, []string{topologyRegionKey, topologyVPCKey, topologySubnetKey, topologyHostKey}, assetKeys(graph.PathTo(topologyHostKey)))
+		assert.Equal(t, []string{topologyRegionKey}, assetKeys(graph.PathTo(topologyRegionKey)))
+		assert.Nil(t, graph.PathTo("/aws/us-east-1/host/i-2"))
+		assert.Empty(t, graph.Children(topologyHostKey))
+		assert.Equal(t, []string{topologyHostKey}, assetKeys(graph.AssetsOfType(AssetTypeHost)))
+
+		vpc, ok := graph.Asset(topologyVPCKey)
+		if assert.True(t, ok) {
+			cidr, _ := vpc.StringProperty("cidr_block")
+			assert.Equal(t, "10.0.0.0/16", cidr)
+			assert.False(t, vpc.HasProperty("children"))
+		}
+		_, ok = graph.Asset("/aws/us-east-1/host/i-2")
+		assert.False(t, ok)
+	}
+}
+
+func TestAssetsTopology_WriteDOT(t *testing.T) {
+	graph := newAssetGraph(testDeploymentId)
+	graph.addNode(topologyNode{
+		Asset: Asset{Key: topologyVPCKey, Type: AssetTypeVPC, Name: "main"},
+		Children: []topologyNode{
+			{Asset: Asset{Key: topologySGKey, Type: AssetTypeSecurityGroup, Name: `web "public"`}},
+			{Asset: Asset{Key: topologySubnetKey, Type: AssetTypeSubnet}},
+		},
+	}, "")
+
+	var buf bytes.Buffer
+	err := graph.WriteDOT(&buf)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `digraph "`+testDeploymentId+`" {
+  rankdir=LR;
+  "/aws/us-east-1/sg/sg-1" [label="sg\nweb \"public\""];
+  "/aws/us-east-1/subnet/subnet-1" [label="subnet\n/aws/us-east-1/subnet/subnet-1"];
+  "/aws/us-east-1/vpc/vpc-1" [label="vpc\nmain"];
+  "/aws/us-east-1/vpc/vpc-1" -> "/aws/us-east-1/sg/sg-1";
+  "/aws/us-east-1/vpc/vpc-1" -> "/aws/us-east-1/subnet/subnet-1";
+}
+`, buf.String())
+	}
+}
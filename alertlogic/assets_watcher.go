@@ -0,0 +1,292 @@
+package alertlogic
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultAssetWatchInterval is the default interval between polls of an AssetWatcher.
+const DefaultAssetWatchInterval = 5 * time.Minute
+
+// AssetEventType is the kind of an AssetEvent.
+type AssetEventType string
+
+const (
+	AssetAdded    AssetEventType = "added"
+	AssetModified AssetEventType = "modified"
+	AssetRemoved  AssetEventType = "removed"
+)
+
+// AssetEvent is a change to a single asset between two polls. `Asset` is the asset as last seen,
+// and `Previous` is the asset as seen by the poll before for modified and removed assets.
+type AssetEvent struct {
+	Type     AssetEventType `json:"type"`
+	Key      string         `json:"key"`
+	Asset    Asset          `json:"asset"`
+	Previous *Asset         `json:"previous,omitempty"`
+	Time     time.Time      `json:"time"`
+}
+
+// AssetSnapshot holds the assets seen by a poll, keyed by asset key.
+type AssetSnapshot map[string]Asset
+
+// SnapshotStore persists the last AssetSnapshot of an AssetWatcher, so that a restarted watcher
+// only reports changes made since it last ran. Load returns a nil snapshot and no error if no
+// snapshot has been saved.
+type SnapshotStore interface {
+	Load() (AssetSnapshot, error)
+	Save(snapshot AssetSnapshot) error
+}
+
+// MemorySnapshotStore keeps a snapshot in memory. It is safe for concurrent use.
+type MemorySnapshotStore struct {
+	mu       sync.Mutex
+	snapshot AssetSnapshot
+}
+
+// NewMemorySnapshotStore creates an empty in-memory snapshot store.
+func NewMemorySnapshotStore() *MemorySnapshotStore {
+	return &MemorySnapshotStore{}
+}
+
+// Load returns the saved snapshot.
+func (s *MemorySnapshotStore) Load() (AssetSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.snapshot, nil
+}
+
+// Save replaces the saved snapshot.
+func (s *MemorySnapshotStore) Save(snapshot AssetSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshot = snapshot
+	return nil
+}
+
+// FileSnapshotStore keeps a snapshot in a JSON file at `Path`. The file is replaced atomically on
+// every save.
+type FileSnapshotStore struct {
+	Path string
+}
+
+// NewFileSnapshotStore creates a snapshot store that uses the file at `path`.
+func NewFileSnapshotStore(path string) *FileSnapshotStore {
+	return &FileSnapshotStore{Path: path}
+}
+
+// Load reads the snapshot from the file, returning a nil snapshot if the file does not exist.
+func (s *FileSnapshotStore) Load() (AssetSnapshot, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading asset snapshot")
+	}
+
+	snapshot := AssetSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, errors.Wrap(err, "error reading asset snapshot")
+	}
+
+	return snapshot, nil
+}
+
+// Save writes the snapshot to a temporary file and renames it over the file.
+func (s *FileSnapshotStore) Save(snapshot AssetSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.Wrap(err, "error writing asset snapshot")
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "error writing asset snapshot")
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return errors.Wrap(err, "error writing asset snapshot")
+	}
+	if err := file.Close(); err != nil {
+		return errors.Wrap(err, "error writing asset snapshot")
+	}
+
+	return errors.Wrap(os.Rename(file.Name(), s.Path), "error writing asset snapshot")
+}
+
+// AssetWatcherOptions control an AssetWatcher.
+// `Interval` defaults to DefaultAssetWatchInterval and `Store` to a new MemorySnapshotStore.
+// When no snapshot has been saved, the first poll only records a snapshot unless `EmitInitial`
+// is set, in which case every asset is reported as added. `OnError` is called with errors from
+// polls, which are otherwise ignored; the next poll is made as usual.
+type AssetWatcherOptions struct {
+	Interval    time.Duration
+	Store       SnapshotStore
+	EmitInitial bool
+	OnError     func(err error)
+}
+
+// AssetWatcher polls an asset query and reports the assets that were added, modified or removed
+// since the previous poll.
+type AssetWatcher struct {
+	api     *API
+	query   *AssetQuery
+	options AssetWatcherOptions
+	events  chan AssetEvent
+}
+
+// NewAssetWatcher creates a watcher for the assets returned by `query`. Start it with Run.
+func (api *API) NewAssetWatcher(query *AssetQuery, options AssetWatcherOptions) *AssetWatcher {
+	if options.Interval <= 0 {
+		options.Interval = DefaultAssetWatchInterval
+	}
+	if options.Store == nil {
+		options.Store = NewMemorySnapshotStore()
+	}
+
+	return &AssetWatcher{
+		api:     api,
+		query:   query,
+		options: options,
+		events:  make(chan AssetEvent),
+	}
+}
+
+// Events returns the channel that Run sends events on. It is closed when Run returns.
+func (w *AssetWatcher) Events() <-chan AssetEvent {
+	return w.events
+}
+
+// Run polls immediately and then every interval, sending the events of each poll on Events,
+// until `ctx` is done. It returns the context's error.
+// A snapshot is only saved once all of its events have been received, so events that were not
+// received before `ctx` was done are sent again by the next run.
+func (w *AssetWatcher) Run(ctx context.Context) error {
+	defer close(w.events)
+
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.poll(ctx); err != nil && ctx.Err() == nil && w.options.OnError != nil {
+			w.options.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll queries the assets once and returns the events since the saved snapshot, saving the new
+// snapshot. It can be used instead of Run to poll on a custom schedule.
+func (w *AssetWatcher) Poll() ([]AssetEvent, error) {
+	events, current, err := w.changes()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.options.Store.Save(current); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// poll polls once and sends the events on the events channel.
+func (w *AssetWatcher) poll(ctx context.Context) error {
+	events, current, err := w.changes()
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		select {
+		case w.events <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return w.options.Store.Save(current)
+}
+
+// changes queries the current snapshot and returns the events since the saved snapshot, or no
+// events if there is no saved snapshot and `EmitInitial` is not set.
+func (w *AssetWatcher) changes() ([]AssetEvent, AssetSnapshot, error) {
+	previous, err := w.options.Store.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := w.api.QueryAssets(w.query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := AssetSnapshot{}
+	for _, row := range result.Assets {
+		for _, asset := range row {
+			if asset.Key != "" && asset.DeletedOn == 0 {
+				current[asset.Key] = asset
+			}
+		}
+	}
+
+	if previous == nil && !w.options.EmitInitial {
+		return []AssetEvent{}, current, nil
+	}
+
+	return DiffAssetSnapshots(previous, current, timeNow()), current, nil
+}
+
+// DiffAssetSnapshots returns the events that turn `previous` into `current`, sorted by asset key.
+// An asset is modified if its state, version, modification time or deletion time changed.
+// Deleted assets are left out of snapshots, so they are reported as removed.
+func DiffAssetSnapshots(previous AssetSnapshot, current AssetSnapshot, now time.Time) []AssetEvent {
+	keys := map[string]bool{}
+	for key := range previous {
+		keys[key] = true
+	}
+	for key := range current {
+		keys[key] = true
+	}
+
+	events := []AssetEvent{}
+	for _, key := range sortedKeys(keys) {
+		before, wasSeen := previous[key]
+		after, isSeen := current[key]
+
+		switch {
+		case !wasSeen:
+			events = append(events, AssetEvent{Type: AssetAdded, Key: key, Asset: after, Time: now})
+		case !isSeen:
+			events = append(events, AssetEvent{Type: AssetRemoved, Key: key, Asset: before, Previous: &before, Time: now})
+		case assetChanged(before, after):
+			events = append(events, AssetEvent{Type: AssetModified, Key: key, Asset: after, Previous: &before, Time: now})
+		}
+	}
+
+	return events
+}
+
+// assetChanged reports whether an asset changed between two polls.
+func assetChanged(before Asset, after Asset) bool {
+	return before.State != after.State ||
+		before.Version != after.Version ||
+		before.ModifiedOn != after.ModifiedOn ||
+		before.DeletedOn != after.DeletedOn
+}
@@ -0,0 +1,264 @@
+package alertlogic
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testWatcherNow = 1640995200
+
+// watcherReplies are the successive responses to asset queries in watcher tests.
+var watcherReplies = []string{
+	`{"rows": 2, "assets": [
+		[{"key": "/aws/us-east-1/host/i-1", "type": "host", "state": "running", "modified_on": 1}],
+		[{"key": "/aws/us-east-1/host/i-2", "type": "host", "state": "running", "modified_on": 1}]
+	]}`,
+	`{"rows": 3, "assets": [
+		[{"key": "/aws/us-east-1/host/i-1", "type": "host", "state": "stopped", "modified_on": 2}],
+		[{"key": "/aws/us-east-1/host/i-2", "type": "host", "state": "running", "modified_on": 1, "deleted_on": 2}],
+		[{"key": "/aws/us-east-1/host/i-3", "type": "host", "state": "running", "modified_on": 2}]
+	]}`,
+	`{"rows": 1, "assets": [
+		[{"key": "/aws/us-east-1/host/i-1", "type": "host", "state": "stopped", "modified_on": 2}],
+		[{"key": "/aws/us-east-1/host/i-3", "type": "host", "state": "running", "modified_on": 2, "name": "renamed"}]
+	]}`,
+}
+
+// watcherHandler serves `watcherReplies` in turn, repeating the last one, and returns a function
+// that reports how many queries were made.
+func watcherHandler(t *testing.T) func() int {
+	var mu sync.Mutex
+	queries := 0
+
+	mux.HandleFunc(getExternalDNSNameAssetsPath, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, "h:host", r.URL.Query().Get("asset_types"))
+
+		reply := watcherReplies[len(watcherReplies)-1]
+		if queries < len(watcherReplies) {
+			reply = watcherReplies[queries]
+		}
+		queries++
+
+		fmt.Fprint(w, reply)
+	})
+
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return queries
+	}
+}
+
+// eventSummaries describes events as "type key".
+func eventSummaries(events []AssetEvent) []string {
+	summaries := make([]string, len(events))
+	for i, event := range events {
+		summaries[i] = fmt.Sprintf("%s %s", event.Type, event.Key)
+	}
+
+	return summaries
+}
+
+func TestAssetsWatcher_Poll(t *testing.T) {
+	setup()
+	defer teardown()
+
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(testWatcherNow, 0) }
+
+	watcherHandler(t)
+	watcher := client.NewAssetWatcher(NewAssetQuery().AssetType("h", AssetTypeHost), AssetWatcherOptions{})
+
+	events, err := watcher.Poll()
+	if assert.NoError(t, err) {
+		assert.Empty(t, events)
+	}
+
+	events, err = watcher.Poll()
+	if assert.NoError(t, err) && assert.Len(t, events, 3) {
+		assert.Equal(t, []string{
+			"modified /aws/us-east-1/host/i-1",
+			"removed /aws/us-east-1/host/i-2",
+			"added /aws/us-east-1/host/i-3",
+		}, eventSummaries(events))
+
+		assert.Equal(t, "stopped", events[0].Asset.State)
+		assert.Equal(t, "running", events[0].Previous.State)
+		assert.Equal(t, time.Unix(testWatcherNow, 0), events[0].Time)
+		assert.Equal(t, "/aws/us-east-1/host/i-2", events[1].Asset.Key)
+		assert.Nil(t, events[2].Previous)
+	}
+
+	events, err = watcher.Poll()
+	if assert.NoError(t, err) {
+		assert.Empty(t, events, "name changes alone are not reported")
+	}
+}
+
+func TestAssetsWatcher_PollEmitInitial(t *testing.T) {
+	setup()
+	defer teardown()
+
+	watcherHandler(t)
+	watcher := client.NewAssetWatcher(NewAssetQuery().AssetType("h", AssetTypeHost), AssetWatcherOptions{EmitInitial: true})
+
+	events, err := watcher.Poll()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"added /aws/us-east-1/host/i-1", "added /aws/us-east-1/host/i-2"}, eventSummaries(events))
+	}
+}
+
+func TestAssetsWatcher_PollError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	store := NewMemorySnapshotStore()
+	watcher := client.NewAssetWatcher(NewAssetQuery().AssetType("h", AssetTypeHost), AssetWatcherOptions{Store: store})
+
+	_, err := watcher.Poll()
+	assert.Contains(t, err.Error(), "HTTP status 404")
+
+	snapshot, _ := store.Load()
+	assert.Nil(t, snapshot)
+
+	_, err = client.NewAssetWatcher(NewAssetQuery(), AssetWatcherOptions{}).Poll()
+	assert.EqualError(t, err, errEmptyAssetQuery)
+}
+
+func TestAssetsWatcher_Run(t *testing.T) {
+	setup()
+	defer teardown()
+
+	queries := watcherHandler(t)
+	store := NewMemorySnapshotStore()
+	watcher := client.NewAssetWatcher(NewAssetQuery().AssetType("h", AssetTypeHost), AssetWatcherOptions{
+		Interval:    10 * time.Millisecond,
+		Store:       store,
+		EmitInitial: true,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+
+	events := []AssetEvent{}
+	for event := range watcher.Events() {
+		events = append(events, event)
+		if len(events) == 5 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, context.Canceled, <-done)
+	assert.Equal(t, []string{
+		"added /aws/us-east-1/host/i-1",
+		"added /aws/us-east-1/host/i-2",
+		"modified /aws/us-east-1/host/i-1",
+		"removed /aws/us-east-1/host/i-2",
+		"added /aws/us-east-1/host/i-3",
+	}, eventSummaries(events))
+	assert.GreaterOrEqual(t, queries(), 2)
+
+	snapshot, _ := store.Load()
+	assert.Contains(t, snapshot, "/aws/us-east-1/host/i-3")
+}
+
+func TestAssetsWatcher_RunOnError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errs := []error{}
+	watcher := client.NewAssetWatcher(NewAssetQuery().AssetType("h", AssetTypeHost), AssetWatcherOptions{
+		Interval: time.Millisecond,
+		OnError: func(err error) {
+			errs = append(errs, err)
+			if len(errs) == 2 {
+				cancel()
+			}
+		},
+	})
+
+	assert.Equal(t, context.Canceled, watcher.Run(ctx))
+	assert.Len(t, errs, 2)
+
+	_, open := <-watcher.Events()
+	assert.False(t, open)
+}
+
+func TestAssetsWatcher_FileSnapshotStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "alertlogic")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileSnapshotStore(filepath.Join(dir, "snapshot.json"))
+
+	snapshot, err := store.Load()
+	assert.NoError(t, err)
+	assert.Nil(t, snapshot)
+
+	var asset Asset
+	assert.NoError(t, asset.UnmarshalJSON([]byte(`{"key": "/aws/us-east-1/host/i-1", "type": "host", "state": "running", "instance_type": "t3.micro"}`)))
+
+	if assert.NoError(t, store.Save(AssetSnapshot{asset.Key: asset})) {
+		snapshot, err = store.Load()
+		if assert.NoError(t, err) && assert.Contains(t, snapshot, asset.Key) {
+			loaded := snapshot[asset.Key]
+			assert.Equal(t, "running", loaded.State)
+			instanceType, _ := loaded.StringProperty("instance_type")
+			assert.Equal(t, "t3.micro", instanceType)
+		}
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1)
+
+	assert.NoError(t, ioutil.WriteFile(store.Path, []byte("not json"), 0600))
+	_, err = store.Load()
+	assert.Contains(t, err.Error(), "error reading asset snapshot")
+
+	err = NewFileSnapshotStore(filepath.Join(dir, "missing", "snapshot.json")).Save(AssetSnapshot{})
+	assert.Contains(t, err.Error(), "error writing asset snapshot")
+}
+
+func TestAssetsWatcher_FileSnapshotStoreSurvivesRestart(t *testing.T) {
+	setup()
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "alertlogic")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	watcherHandler(t)
+	query := NewAssetQuery().AssetType("h", AssetTypeHost)
+	path := filepath.Join(dir, "snapshot.json")
+
+	_, err = client.NewAssetWatcher(query, AssetWatcherOptions{Store: NewFileSnapshotStore(path), EmitInitial: true}).Poll()
+	assert.NoError(t, err)
+
+	events, err := client.NewAssetWatcher(query, AssetWatcherOptions{Store: NewFileSnapshotStore(path), EmitInitial: true}).Poll()
+	if assert.NoError(t, err) {
+		assert.Len(t, events, 3)
+	}
+}
@@ -2,6 +2,7 @@ package alertlogic
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 )
@@ -13,6 +14,13 @@ type ExternalDNSAssetRequest struct {
 	Properties    map[string]string `json:"properties,omitempty"`
 	Relationships []Relationship    `json:"relationships,omitempty"`
 	Key           string            `json:"key,omitempty"`
+	Tags          []AssetTag        `json:"tags,omitempty"`
+}
+
+// AssetTag is a single tag in an asset write request. `Value` is omitted when removing tags.
+type AssetTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
 }
 
 type Relationship struct {
@@ -20,7 +28,132 @@ type Relationship struct {
 	Type string `json:"type,omitempty"`
 }
 
+// AssetDeclaration describes an asset to declare or remove with DeclareAsset or RemoveAsset.
+// `Scope` is the platform of the deployment, for example `aws`, `azure` or `datacenter`. If it
+// is empty, it is inferred from the deployment's platform type.
+type AssetDeclaration struct {
+	Type          string                 `json:"type"`
+	Scope         string                 `json:"scope"`
+	Key           string                 `json:"key"`
+	Properties    map[string]interface{} `json:"properties,omitempty"`
+	Relationships []Relationship         `json:"relationships,omitempty"`
+}
+
+// assetWriteRequest is the body of a request that declares or removes an asset.
+type assetWriteRequest struct {
+	Operation string `json:"operation"`
+	AssetDeclaration
+}
+
+// ExternalDNSNameDeclaration returns a declaration of an asset of the type `external-dns-name`.
+// `dnsName` is normalized with NormalizeDNSName, and a *DNSNameError is returned if it is not
+// valid.
+func ExternalDNSNameDeclaration(dnsName string) (AssetDeclaration, error) {
+	dnsName, err := NormalizeDNSName(dnsName)
+	if err != nil {
+		return AssetDeclaration{}, err
+	}
+
+	return AssetDeclaration{
+		Type: AssetTypeExternalDNSName,
+		Key:  externalDNSNameKey(dnsName),
+		Properties: map[string]interface{}{
+			"dns_name": dnsName,
+			"name":     dnsName,
+			"state":    "new",
+		},
+	}, nil
+}
+
+// externalDNSNameKey returns the asset key of an external DNS name.
+func externalDNSNameKey(dnsName string) string {
+	return fmt.Sprintf("/external-dns-name/%s", dnsName)
+}
+
+// ExternalIPDeclaration returns a declaration of an asset of the type `external-ip`.
+func ExternalIPDeclaration(ipAddress string) AssetDeclaration {
+	return AssetDeclaration{
+		Type: AssetTypeExternalIP,
+		Key:  fmt.Sprintf("/external-ip/%s", ipAddress),
+		Properties: map[string]interface{}{
+			"ip_address": ipAddress,
+			"name":       ipAddress,
+			"state":      "new",
+		},
+	}
+}
+
+// NetworkDeclaration returns a declaration of a data center network with the given CIDR ranges.
+// Networks are declared as assets of the type `vpc`.
+func NetworkDeclaration(networkId string, name string, cidrRanges ...string) AssetDeclaration {
+	return AssetDeclaration{
+		Type: AssetTypeVPC,
+		Key:  fmt.Sprintf("/dc/network/%s", networkId),
+		Properties: map[string]interface{}{
+			"network_name": name,
+			"cidr_ranges":  cidrRanges,
+		},
+	}
+}
+
+// SubnetDeclaration returns a declaration of a subnet of the data center network with
+// `networkId`, related to the network's asset.
+func SubnetDeclaration(networkId string, subnetId string, name string, cidrBlock string) AssetDeclaration {
+	return AssetDeclaration{
+		Type: AssetTypeSubnet,
+		Key:  fmt.Sprintf("/dc/network/%s/subnet/%s", networkId, subnetId),
+		Properties: map[string]interface{}{
+			"subnet_name": name,
+			"cidr_block":  cidrBlock,
+		},
+		Relationships: []Relationship{
+			{Key: fmt.Sprintf("/dc/network/%s", networkId), Type: AssetTypeVPC},
+		},
+	}
+}
+
+// DeclareAsset creates or updates an asset of any type in a deployment.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-DeclareAsset
+func (api *API) DeclareAsset(deploymentId string, asset AssetDeclaration) (int, error) {
+	return api.writeAsset(deploymentId, "declare_asset", asset)
+}
+
+// RemoveAsset removes an asset of any type from a deployment. Only the type, scope and key of
+// `asset` are used.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-RemoveAsset
+func (api *API) RemoveAsset(deploymentId string, asset AssetDeclaration) (int, error) {
+	return api.writeAsset(deploymentId, "remove_asset", AssetDeclaration{Type: asset.Type, Scope: asset.Scope, Key: asset.Key})
+}
+
+// writeAsset holds shared logic for declaring or removing an asset, inferring its scope from
+// the deployment if it is not set.
+func (api *API) writeAsset(deploymentId string, operation string, asset AssetDeclaration) (int, error) {
+	if asset.Type == "" || asset.Key == "" {
+		return 0, errors.New(errInvalidAssetDeclaration)
+	}
+
+	if asset.Scope == "" {
+		scope, err := api.deploymentScope(deploymentId)
+		if err != nil {
+			return 0, err
+		}
+		asset.Scope = scope
+	}
+
+	_, statusCode, err := api.makeRequest("PUT", fmt.Sprintf("%s/%s/deployments/%s/assets", assetsWriteServicePath, api.AccountID, deploymentId), nil, nil, assetWriteRequest{Operation: operation, AssetDeclaration: asset})
+
+	if err != nil {
+		return statusCode, errors.Wrap(err, errMakeRequestError)
+	}
+
+	return statusCode, nil
+}
+
 // CreateExternalDNSNameAsset creates a new asset of the type `external-dns-name` for AWS.
+// `dnsName` is normalized with NormalizeDNSName, and a *DNSNameError is returned if it is not
+// valid.
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-DeclareAsset
 func (api *API) CreateExternalDNSNameAsset(deploymentId string, dnsName string) (int, error) {
@@ -28,51 +161,109 @@ func (api *API) CreateExternalDNSNameAsset(deploymentId string, dnsName string)
 }
 
 // UpdateExternalDNSNameAsset updates an existing asset of the type `external-dns-name` for AWS.
-// The only item that you may update is the DNS name of the asset.
+// The only item that you may update is the DNS name of the asset. Both names are normalized with
+// NormalizeDNSName, so the asset is found by the normalized key of `oldDnsName`. Use
+// UpdateExternalDNSNameAssetByKey for assets declared under keys that are not normalized.
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-DeclareAsset
 func (api *API) UpdateExternalDNSNameAsset(deploymentId string, dnsName string, oldDnsName string) (int, error) {
-	return api.modifyExternalDNSNameAsset(deploymentId, dnsName, oldDnsName)
+	oldDnsName, err := NormalizeDNSName(oldDnsName)
+	if err != nil {
+		return 0, err
+	}
+
+	return api.modifyExternalDNSNameAsset(deploymentId, dnsName, externalDNSNameKey(oldDnsName))
 }
 
-// RemoveExternalDNSNameAsset creates a new asset of the type `external-dns-name` for AWS.
+// UpdateExternalDNSNameAssetByKey updates the DNS name of an existing asset of the type
+// `external-dns-name` for AWS by its asset key, which is used as is. Use it to update assets
+// whose names were declared before they were normalized, such as `/external-dns-name/Foo.com.`.
+// `dnsName` is normalized with NormalizeDNSName.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-DeclareAsset
+func (api *API) UpdateExternalDNSNameAssetByKey(deploymentId string, dnsName string, key string) (int, error) {
+	if key == "" {
+		return 0, errors.New(errInvalidAssetDeclaration)
+	}
+
+	return api.modifyExternalDNSNameAsset(deploymentId, dnsName, key)
+}
+
+// RemoveExternalDNSNameAsset removes an asset of the type `external-dns-name` for AWS.
+// `dnsName` is normalized with NormalizeDNSName.
 //
 // API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-RemoveAsset
 func (api *API) RemoveExternalDNSNameAsset(deploymentId string, dnsName string) (int, error) {
-	asset := ExternalDNSAssetRequest{
-		Operation: "remove_asset",
-		Type:      "external-dns-name",
-		Scope:     "aws",
-		Key:       fmt.Sprintf("/external-dns-name/%s", dnsName),
+	dnsName, err := NormalizeDNSName(dnsName)
+	if err != nil {
+		return 0, err
 	}
 
-	_, statusCode, err := api.makeRequest("PUT", fmt.Sprintf("%s/%s/deployments/%s/assets", assetsWriteServicePath, api.AccountID, deploymentId), nil, nil, asset)
+	return api.RemoveExternalDNSNameAssetByKey(deploymentId, externalDNSNameKey(dnsName))
+}
 
-	if err != nil {
-		return statusCode, errors.Wrap(err, errMakeRequestError)
+// RemoveExternalDNSNameAssetByKey removes an asset of the type `external-dns-name` for AWS by its
+// asset key, which is used as is. Use it to remove assets whose names were declared before they
+// were normalized, or that are not valid DNS names.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-RemoveAsset
+func (api *API) RemoveExternalDNSNameAssetByKey(deploymentId string, key string) (int, error) {
+	return api.RemoveAsset(deploymentId, AssetDeclaration{Type: AssetTypeExternalDNSName, Scope: "aws", Key: key})
+}
+
+// TagExternalDNSNameAsset sets `tags` on an existing asset of the type `external-dns-name` for
+// AWS. Tags with other keys are left unchanged.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-DeclareAsset
+func (api *API) TagExternalDNSNameAsset(deploymentId string, dnsName string, tags Tags) (int, error) {
+	if len(tags) == 0 {
+		return 0, errors.New(errEmptyTags)
 	}
 
-	return statusCode, nil
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	assetTags := make([]AssetTag, len(keys))
+	for i, key := range keys {
+		assetTags[i] = AssetTag{Key: key, Value: tags[key]}
+	}
+
+	return api.tagExternalDNSNameAsset(deploymentId, dnsName, "tag_asset", assetTags)
 }
 
-// modifyExternalDNSNameAsset holds shared logic for creating or modifying an external DNS
-// asset.
-func (api *API) modifyExternalDNSNameAsset(deploymentId string, dnsName string, oldDnsName string) (int, error) {
-	keyDns := dnsName
-	if oldDnsName != "" {
-		keyDns = oldDnsName
+// UntagExternalDNSNameAsset removes the tags with `keys` from an existing asset of the type
+// `external-dns-name` for AWS.
+//
+// API reference: https://console.cloudinsight.alertlogic.com/api/assets_write/#api-DeclareModify-DeclareAsset
+func (api *API) UntagExternalDNSNameAsset(deploymentId string, dnsName string, keys ...string) (int, error) {
+	if len(keys) == 0 {
+		return 0, errors.New(errEmptyTags)
+	}
+
+	assetTags := make([]AssetTag, len(keys))
+	for i, key := range keys {
+		assetTags[i] = AssetTag{Key: key}
+	}
+
+	return api.tagExternalDNSNameAsset(deploymentId, dnsName, "untag_asset", assetTags)
+}
+
+// tagExternalDNSNameAsset holds shared logic for tagging or untagging an external DNS asset.
+func (api *API) tagExternalDNSNameAsset(deploymentId string, dnsName string, operation string, tags []AssetTag) (int, error) {
+	dnsName, err := NormalizeDNSName(dnsName)
+	if err != nil {
+		return 0, err
 	}
 
 	asset := ExternalDNSAssetRequest{
-		Operation: "declare_asset",
+		Operation: operation,
 		Type:      "external-dns-name",
 		Scope:     "aws",
-		Key:       fmt.Sprintf("/external-dns-name/%s", keyDns),
-		Properties: map[string]string{
-			"dns_name": dnsName,
-			"name":     dnsName,
-			"state":    "new",
-		},
+		Key:       externalDNSNameKey(dnsName),
+		Tags:      tags,
 	}
 
 	_, statusCode, err := api.makeRequest("PUT", fmt.Sprintf("%s/%s/deployments/%s/assets", assetsWriteServicePath, api.AccountID, deploymentId), nil, nil, asset)
@@ -83,3 +274,32 @@ func (api *API) modifyExternalDNSNameAsset(deploymentId string, dnsName string,
 
 	return statusCode, nil
 }
+
+// deploymentScope returns the asset scope of a deployment, which is its platform type.
+func (api *API) deploymentScope(deploymentId string) (string, error) {
+	deployment, err := api.GetDeployment(deploymentId)
+	if err != nil {
+		return "", errors.Wrap(err, "error inferring asset scope")
+	}
+	if deployment.Platform.Type == "" {
+		return "", errors.Errorf("deployment %s has no platform type to infer the asset scope from", deploymentId)
+	}
+
+	return deployment.Platform.Type, nil
+}
+
+// modifyExternalDNSNameAsset holds shared logic for creating or modifying an external DNS
+// asset. An empty `key` creates the asset under the normalized key of `dnsName`.
+func (api *API) modifyExternalDNSNameAsset(deploymentId string, dnsName string, key string) (int, error) {
+	asset, err := ExternalDNSNameDeclaration(dnsName)
+	if err != nil {
+		return 0, err
+	}
+
+	asset.Scope = "aws"
+	if key != "" {
+		asset.Key = key
+	}
+
+	return api.DeclareAsset(deploymentId, asset)
+}
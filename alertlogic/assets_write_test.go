@@ -2,6 +2,7 @@ package alertlogic
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"testing"
 
@@ -125,3 +126,259 @@ func TestAssetsWrite_RemoveExternalDNSNameAssetError(t *testing.T) {
 	assert.Equal(t, respCode, http.StatusBadRequest)
 	assert.Equal(t, err.Error(), fmt.Sprintf("error from makeRequest: %s", errorResponse))
 }
+
+func TestAssetsWrite_TagExternalDNSNameAsset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{
+			"operation": "tag_asset",
+			"type": "external-dns-name",
+			"scope": "aws",
+			"key": "/external-dns-name/abcd-1234.elb.us-east-1.amazonaws.com",
+			"tags": [{"key": "env", "value": "prod"}, {"key": "team", "value": "web"}]
+		}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	status, err := client.TagExternalDNSNameAsset(testDeploymentId, "abcd-1234.elb.us-east-1.amazonaws.com", Tags{"team": "web", "env": "prod"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusNoContent, status)
+	}
+}
+
+func TestAssetsWrite_UntagExternalDNSNameAsset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{
+			"operation": "untag_asset",
+			"type": "external-dns-name",
+			"scope": "aws",
+			"key": "/external-dns-name/abcd-1234.elb.us-east-1.amazonaws.com",
+			"tags": [{"key": "env"}]
+		}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	status, err := client.UntagExternalDNSNameAsset(testDeploymentId, "abcd-1234.elb.us-east-1.amazonaws.com", "env")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusNoContent, status)
+	}
+}
+
+func TestAssetsWrite_TagExternalDNSNameAssetErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.TagExternalDNSNameAsset(testDeploymentId, "abcd-1234.elb.us-east-1.amazonaws.com", nil)
+	assert.EqualError(t, err, errEmptyTags)
+
+	_, err = client.UntagExternalDNSNameAsset(testDeploymentId, "abcd-1234.elb.us-east-1.amazonaws.com")
+	assert.EqualError(t, err, errEmptyTags)
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	status, err := client.TagExternalDNSNameAsset(testDeploymentId, "abcd-1234.elb.us-east-1.amazonaws.com", Tags{"env": "prod"})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestAssetsWrite_LegacyExternalDNSNameAssetBodies(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var body string
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	_, err := client.UpdateExternalDNSNameAsset(testDeploymentId, "qwert-9876.elb.us-east-1.amazonaws.com", "abcd-1234.elb.us-east-1.amazonaws.com")
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"operation": "declare_asset",
+			"type": "external-dns-name",
+			"scope": "aws",
+			"key": "/external-dns-name/abcd-1234.elb.us-east-1.amazonaws.com",
+			"properties": {"dns_name": "qwert-9876.elb.us-east-1.amazonaws.com", "name": "qwert-9876.elb.us-east-1.amazonaws.com", "state": "new"}
+		}`, body)
+	}
+
+	_, err = client.RemoveExternalDNSNameAsset(testDeploymentId, "qwert-9876.elb.us-east-1.amazonaws.com")
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"operation": "remove_asset",
+			"type": "external-dns-name",
+			"scope": "aws",
+			"key": "/external-dns-name/qwert-9876.elb.us-east-1.amazonaws.com"
+		}`, body)
+	}
+}
+
+func TestAssetsWrite_DeclareAsset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getDeploymentPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method, "Expected method 'GET', got %s", r.Method)
+		fmt.Fprint(w, `{"id": "50668317-feb8-49d1-b401-7219bfa22417", "platform": {"type": "datacenter"}}`)
+	})
+
+	var body string
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	var declareTests = []struct {
+		asset AssetDeclaration
+		body  string
+	}{
+		{
+			NetworkDeclaration("N1", "Office", "10.0.0.0/16", "10.1.0.0/16"),
+			`{"operation": "declare_asset", "type": "vpc", "scope": "datacenter", "key": "/dc/network/N1",
+			  "properties": {"network_name": "Office", "cidr_ranges": ["10.0.0.0/16", "10.1.0.0/16"]}}`,
+		},
+		{
+			SubnetDeclaration("N1", "S1", "Servers", "10.0.1.0/24"),
+			`{"operation": "declare_asset", "type": "subnet", "scope": "datacenter", "key": "/dc/network/N1/subnet/S1",
+			  "properties": {"subnet_name": "Servers", "cidr_block": "10.0.1.0/24"},
+			  "relationships": [{"key": "/dc/network/N1", "type": "vpc"}]}`,
+		},
+		{
+			ExternalIPDeclaration("203.0.113.10"),
+			`{"operation": "declare_asset", "type": "external-ip", "scope": "datacenter", "key": "/external-ip/203.0.113.10",
+			  "properties": {"ip_address": "203.0.113.10", "name": "203.0.113.10", "state": "new"}}`,
+		},
+		{
+			AssetDeclaration{Type: AssetTypeExternalDNSName, Scope: "azure", Key: "/external-dns-name/example.com"},
+			`{"operation": "declare_asset", "type": "external-dns-name", "scope": "azure", "key": "/external-dns-name/example.com"}`,
+		},
+	}
+
+	for _, tt := range declareTests {
+		status, err := client.DeclareAsset(testDeploymentId, tt.asset)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, http.StatusCreated, status)
+			assert.JSONEq(t, tt.body, body)
+		}
+	}
+}
+
+func TestAssetsWrite_RemoveAsset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getDeploymentPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "50668317-feb8-49d1-b401-7219bfa22417", "platform": {"type": "azure"}}`)
+	})
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"operation": "remove_asset", "type": "external-ip", "scope": "azure", "key": "/external-ip/203.0.113.10"}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	status, err := client.RemoveAsset(testDeploymentId, ExternalIPDeclaration("203.0.113.10"))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusNoContent, status)
+	}
+}
+
+func TestAssetsWrite_DeclareAssetErrors(t *testing.T) {
+	setup()
+	defer teardown()
+
+	_, err := client.DeclareAsset(testDeploymentId, AssetDeclaration{Type: AssetTypeExternalIP})
+	assert.EqualError(t, err, errInvalidAssetDeclaration)
+
+	_, err = client.DeclareAsset(testDeploymentId, ExternalIPDeclaration("203.0.113.10"))
+	assert.Contains(t, err.Error(), "error inferring asset scope")
+
+	mux.HandleFunc(getDeploymentPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": "50668317-feb8-49d1-b401-7219bfa22417"}`)
+	})
+
+	_, err = client.RemoveAsset(testDeploymentId, ExternalIPDeclaration("203.0.113.10"))
+	assert.EqualError(t, err, "deployment 50668317-feb8-49d1-b401-7219bfa22417 has no platform type to infer the asset scope from")
+}
+
+func TestAssetsWrite_ExternalDNSNameDeclaration(t *testing.T) {
+	asset, err := ExternalDNSNameDeclaration("WWW.Bücher.example.com.")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "/external-dns-name/www.xn--bcher-kva.example.com", asset.Key)
+		assert.Equal(t, "www.xn--bcher-kva.example.com", asset.Properties["dns_name"])
+	}
+
+	_, err = ExternalDNSNameDeclaration("*.example.com")
+	assert.EqualError(t, err, `invalid DNS name "*.example.com": wildcard names cannot be declared`)
+}
+
+func TestAssetsWrite_RemoveExternalDNSNameAssetByKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"operation": "remove_asset", "type": "external-dns-name", "scope": "aws", "key": "/external-dns-name/*.Example.COM."}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	status, err := client.RemoveExternalDNSNameAssetByKey(testDeploymentId, "/external-dns-name/*.Example.COM.")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusNoContent, status)
+	}
+}
+
+func TestAssetsWrite_UpdateExternalDNSNameAssetByKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(modifyExternalDNSNameAssetPath, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{
+			"operation": "declare_asset", "type": "external-dns-name", "scope": "aws", "key": "/external-dns-name/Foo.com.",
+			"properties": {"dns_name": "foo.com", "name": "foo.com", "state": "new"}
+		}`, string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	status, err := client.UpdateExternalDNSNameAssetByKey(testDeploymentId, "Foo.com.", "/external-dns-name/Foo.com.")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusNoContent, status)
+	}
+
+	_, err = client.UpdateExternalDNSNameAssetByKey(testDeploymentId, "foo.com", "")
+	assert.EqualError(t, err, errInvalidAssetDeclaration)
+}
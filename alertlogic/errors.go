@@ -1,11 +1,121 @@
 package alertlogic
 
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
 // Error messages
 const (
-	errEmptyApiToken               = "API token must not be empty"
-	errEmptyUsernameOrPassword     = "username or password must not be empty"
-	errEmptyAccessKeyIdOrSecretKey = "accessKeyId or secretKey must not be empty"
-	errEmptyAccountId              = "account ID must not be empty"
-	errMakeRequestError            = "error from makeRequest"
-	errUnmarshalError              = "error unmarshalling the JSON response"
+	errEmptyApiToken                    = "API token must not be empty"
+	errEmptyUsernameOrPassword          = "username or password must not be empty"
+	errEmptyAccessKeyIdOrSecretKey      = "accessKeyId or secretKey must not be empty"
+	errEmptyAccountId                   = "account ID must not be empty"
+	errMakeRequestError                 = "error from makeRequest"
+	errUnmarshalError                   = "error unmarshalling the JSON response"
+	errNilAccessKeySink                 = "access key sink must not be nil"
+	errEmptyChangePassword              = "email, current password and new password must not be empty"
+	errEmptyEmail                       = "email must not be empty"
+	errEmptyTokenOrPassword             = "token or password must not be empty"
+	errEmptyPassword                    = "password must not be empty"
+	errEmptyMFAURI                      = "MFA enrollment URI must not be empty"
+	errEmptyMFACodes                    = "at least one MFA code must be supplied"
+	errEmptyAccountName                 = "account name must not be empty"
+	errEmptyUpdateAccountDetailsRequest = "at least one account attribute must be set"
+	errEmptyRoleName                    = "role name must not be empty"
+	errEmptyUser                        = "user must not be empty"
+	errEmptyUpdateUserRequest           = "at least one user attribute must be set"
+	errEmptyAssetQuery                  = "asset query must have at least one asset type"
+	errEmptyTags                        = "at least one tag must be given"
+	errInvalidAssetDeclaration          = "asset type and key must not be empty"
+	errEmptyRemediationIds              = "at least one remediation ID must be given"
+	errEmptyDisposeReason               = "dispose reason must not be empty"
+	errEmptySnoozeExpiry                = "snooze expiry must be set"
+)
+
+// APIError is returned, wrapped, when the API responds with an unsuccessful HTTP status code.
+// Use StatusCode to retrieve the status code from a returned error.
+type APIError struct {
+	StatusCode int
+	message    string
+}
+
+// Error returns the error message.
+func (e *APIError) Error() string {
+	return e.message
+}
+
+// newAPIError creates an APIError with a formatted message.
+func newAPIError(statusCode int, format string, args ...interface{}) *APIError {
+	return &APIError{StatusCode: statusCode, message: fmt.Sprintf(format, args...)}
+}
+
+// StatusCode returns the HTTP status code of the APIError wrapped by `err`, or 0 if `err` was not
+// caused by an unsuccessful API response.
+func StatusCode(err error) int {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode
+	}
+
+	return 0
+}
+
+// isNotFound reports whether `err` was caused by a 404 response from the API.
+func isNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// DNSNameErrorKind is the reason a DNS name failed validation.
+type DNSNameErrorKind string
+
+const (
+	DNSNameEmpty            DNSNameErrorKind = "empty"
+	DNSNameEmptyLabel       DNSNameErrorKind = "empty_label"
+	DNSNameTooLong          DNSNameErrorKind = "name_too_long"
+	DNSNameLabelTooLong     DNSNameErrorKind = "label_too_long"
+	DNSNameWildcard         DNSNameErrorKind = "wildcard"
+	DNSNameInvalidCharacter DNSNameErrorKind = "invalid_character"
+	DNSNameInvalidHyphen    DNSNameErrorKind = "invalid_hyphen"
+	DNSNameInvalidIDN       DNSNameErrorKind = "invalid_idn"
+	DNSNameNumeric          DNSNameErrorKind = "numeric"
 )
+
+// DNSNameError is returned by NormalizeDNSName, and by the functions that use it, when a DNS name
+// is not valid. `Label` is the offending label, if the error is about a single label.
+type DNSNameError struct {
+	Name  string
+	Label string
+	Kind  DNSNameErrorKind
+}
+
+// Error returns the error message.
+func (e *DNSNameError) Error() string {
+	var reason string
+	switch e.Kind {
+	case DNSNameEmpty:
+		reason = "name is empty"
+	case DNSNameEmptyLabel:
+		reason = "name has an empty label"
+	case DNSNameTooLong:
+		reason = fmt.Sprintf("name is longer than %d characters", maxDNSNameLength)
+	case DNSNameLabelTooLong:
+		reason = fmt.Sprintf("label %q is longer than %d characters", e.Label, maxDNSLabelLength)
+	case DNSNameWildcard:
+		reason = "wildcard names cannot be declared"
+	case DNSNameInvalidCharacter:
+		reason = fmt.Sprintf("label %q contains characters other than letters, digits and hyphens", e.Label)
+	case DNSNameInvalidHyphen:
+		reason = fmt.Sprintf("label %q starts or ends with a hyphen", e.Label)
+	case DNSNameInvalidIDN:
+		reason = fmt.Sprintf("label %q is not a valid internationalized label", e.Label)
+	case DNSNameNumeric:
+		reason = "top-level label is numeric; declare IP addresses with ExternalIPDeclaration"
+	default:
+		reason = string(e.Kind)
+	}
+
+	return fmt.Sprintf("invalid DNS name %q: %s", e.Name, reason)
+}
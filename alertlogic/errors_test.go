@@ -40,7 +40,23 @@ var tests = []RequestError{
 		Method:       "POST",
 		FunctionName: "UpdateAccountDetails",
 		Arguments: []interface{}{
-			UpdateAccountDetailsRequest{MfaRequired: false},
+			UpdateAccountDetailsRequest{MfaRequired: Bool(false)},
+		},
+	},
+	{
+		Group:        "accounts",
+		Path:         fmt.Sprintf("/%s/%s/accounts/%s", aimsServicePath, testAccountId, Managed),
+		Method:       "GET",
+		FunctionName: "ListManagedAccounts",
+		Arguments:    nil,
+	},
+	{
+		Group:        "accounts",
+		Path:         fmt.Sprintf("/%s/%s/accounts/%s", aimsServicePath, testAccountId, Managed),
+		Method:       "POST",
+		FunctionName: "CreateManagedAccount",
+		Arguments: []interface{}{
+			CreateAccountRequest{Name: "Child Company"},
 		},
 	},
 	{
@@ -75,6 +91,25 @@ var tests = []RequestError{
 			testRoleId,
 		},
 	},
+	{
+		Group:        "roles",
+		Path:         listRolesPath,
+		Method:       "POST",
+		FunctionName: "CreateRole",
+		Arguments: []interface{}{
+			CreateRoleRequest{Name: "Auditors"},
+		},
+	},
+	{
+		Group:        "roles",
+		Path:         getRoleDetailsPath,
+		Method:       "POST",
+		FunctionName: "UpdateRole",
+		Arguments: []interface{}{
+			testRoleId,
+			UpdateRoleRequest{Name: "Auditors"},
+		},
+	},
 	{
 		Group:        "user_roles",
 		Path:         getAssignedRolesPath,
@@ -162,10 +197,28 @@ var tests = []RequestError{
 		FunctionName: "UpdateUserDetails",
 		Arguments: []interface{}{
 			testUserId,
-			UpdateUserRequest{Email: "new@email.com"},
+			UpdateUserRequest{Email: String("new@email.com")},
 			false,
 		},
 	},
+	{
+		Group:        "users",
+		Path:         updateUserPath,
+		Method:       "POST",
+		FunctionName: "ActivateUser",
+		Arguments: []interface{}{
+			testUserId,
+		},
+	},
+	{
+		Group:        "users",
+		Path:         updateUserPath,
+		Method:       "POST",
+		FunctionName: "DeactivateUser",
+		Arguments: []interface{}{
+			testUserId,
+		},
+	},
 	{
 		Group:        "users",
 		Path:         getUserDetailsByUsernamePath,
@@ -190,6 +243,36 @@ var tests = []RequestError{
 			true,
 		},
 	},
+	{
+		Group:        "access_keys",
+		Path:         accessKeysPath,
+		Method:       "GET",
+		FunctionName: "ListAccessKeys",
+		Arguments: []interface{}{
+			testUserId,
+		},
+	},
+	{
+		Group:        "access_keys",
+		Path:         accessKeysPath,
+		Method:       "POST",
+		FunctionName: "CreateAccessKey",
+		Arguments: []interface{}{
+			testUserId,
+			"automation",
+		},
+	},
+	{
+		Group:        "users",
+		Path:         setUserPasswordPath,
+		Method:       "POST",
+		FunctionName: "SetUserPassword",
+		Arguments: []interface{}{
+			testUserId,
+			"password",
+			false,
+		},
+	},
 	{
 		Group:        "assets_query",
 		Path:         getExternalDNSNameAssetsPath,
@@ -197,6 +280,51 @@ var tests = []RequestError{
 		FunctionName: "GetExternalDNSNameAssets",
 		Arguments:    nil,
 	},
+	{
+		Group:        "assets_query",
+		Path:         getExternalDNSNameAssetsPath,
+		Method:       "GET",
+		FunctionName: "QueryAssets",
+		Arguments: []interface{}{
+			NewAssetQuery().AssetType("h", AssetTypeHost),
+		},
+	},
+	{
+		Group:        "assets_query",
+		Path:         getTopologyPath,
+		Method:       "GET",
+		FunctionName: "GetTopology",
+		Arguments: []interface{}{
+			testDeploymentId,
+		},
+	},
+	{
+		Group:        "assets_query",
+		Path:         listExposuresPath,
+		Method:       "GET",
+		FunctionName: "ListExposures",
+		Arguments: []interface{}{
+			ExposureFilter{},
+		},
+	},
+	{
+		Group:        "assets_query",
+		Path:         getExternalDNSNameAssetsPath,
+		Method:       "GET",
+		FunctionName: "QueryVulnerabilities",
+		Arguments: []interface{}{
+			ExposureFilter{},
+		},
+	},
+	{
+		Group:        "assets_query",
+		Path:         listRemediationsPath,
+		Method:       "GET",
+		FunctionName: "ListRemediations",
+		Arguments: []interface{}{
+			RemediationFilter{},
+		},
+	},
 	{
 		Group:        "deployments",
 		Path:         listDeploymentsPath,
@@ -249,3 +377,19 @@ func Test_UnmarshalError(t *testing.T) {
 		assert.Equal(t, err.Error(), testUnmarshalError)
 	}
 }
+
+func Test_StatusCode(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := client.GetUserDetails(testUserId, false, false, false)
+
+	assert.Equal(t, http.StatusNotFound, StatusCode(err))
+	assert.True(t, isNotFound(err))
+	assert.Equal(t, 0, StatusCode(fmt.Errorf("not an API error")))
+	assert.Equal(t, 0, StatusCode(nil))
+}
@@ -0,0 +1,878 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	scimUserSchema      = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema     = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema      = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimPatchOpSchema   = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	scimErrorSchema     = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimContentType     = "application/scim+json"
+	scimUsersEndpoint   = "/Users"
+	scimGroupsEndpoint  = "/Groups"
+	scimInvalidFilter   = "invalidFilter"
+	scimInvalidValue    = "invalidValue"
+	scimInvalidPath     = "invalidPath"
+	scimUniqueness      = "uniqueness"
+	scimInvalidSyntax   = "invalidSyntax"
+	scimMutability      = "mutability"
+	scimDefaultPageSize = 100
+)
+
+// scimFilterRegexp matches the only supported SCIM filter form, `attribute eq "value"`.
+var scimFilterRegexp = regexp.MustCompile(`^\s*([A-Za-z.]+)\s+(?i:eq)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// SCIMUser is a SCIM 2.0 User resource. `UserName` maps to the AIMS user's email address,
+// `DisplayName` and `Name.Formatted` to their name and `Groups` to their roles.
+type SCIMUser struct {
+	Schemas      []string          `json:"schemas"`
+	ID           string            `json:"id,omitempty"`
+	UserName     string            `json:"userName"`
+	Name         *SCIMName         `json:"name,omitempty"`
+	DisplayName  string            `json:"displayName,omitempty"`
+	Emails       []SCIMMultiValued `json:"emails,omitempty"`
+	PhoneNumbers []SCIMMultiValued `json:"phoneNumbers,omitempty"`
+	Active       bool              `json:"active"`
+	Password     string            `json:"password,omitempty"`
+	Groups       []SCIMReference   `json:"groups,omitempty"`
+	Meta         *SCIMMeta         `json:"meta,omitempty"`
+}
+
+// SCIMGroup is a SCIM 2.0 Group resource backed by an AIMS role.
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	DisplayName string          `json:"displayName"`
+	Members     []SCIMReference `json:"members,omitempty"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+// SCIMName is the name of a SCIMUser.
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMMultiValued is a multi-valued attribute entry, such as an email address.
+type SCIMMultiValued struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMReference is a reference from a group to a member or from a user to a group.
+type SCIMReference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMMeta holds resource metadata.
+type SCIMMeta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Version      string `json:"version,omitempty"`
+}
+
+// scimListResponse is a SCIM list response.
+type scimListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// scimPatchRequest is a SCIM PATCH request.
+type scimPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+// scimPatchOperation is a single operation of a SCIM PATCH request.
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// scimError is a SCIM error response.
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+	status   int
+}
+
+// Error returns the error detail.
+func (e *scimError) Error() string {
+	return e.Detail
+}
+
+// newSCIMError creates a SCIM error response.
+func newSCIMError(status int, scimType string, format string, args ...interface{}) *scimError {
+	return &scimError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   fmt.Sprintf(format, args...),
+		status:   status,
+	}
+}
+
+// scimHandler serves SCIM requests for an API client.
+type scimHandler struct {
+	api *API
+}
+
+// NewSCIMHandler returns an http.Handler implementing the SCIM 2.0 `/Users` and `/Groups`
+// endpoints for the account's AIMS users and roles, so that an identity provider can manage
+// Alert Logic users directly.
+//
+// Users map onto CreateUser, UpdateUserDetails, DeleteUser and ListUsersByEmail, with `userName`
+// being the user's email address. Groups are the account's roles, and adding or removing group
+// members calls GrantUserRole and RevokeUserRole. Creating a group creates a role without
+// permissions, and deleting a group deletes its role. Groups cannot be replaced.
+// Only `eq` filters on `userName` and `displayName` are supported.
+//
+// The handler serves `/Users` and `/Groups` at the root of its path; use http.StripPrefix to
+// mount it elsewhere. It does not authenticate requests, so it should be wrapped with whatever
+// authentication the identity provider uses.
+func NewSCIMHandler(api *API) http.Handler {
+	return &scimHandler{api: api}
+}
+
+// ServeHTTP routes a SCIM request.
+func (h *scimHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		status int
+		body   interface{}
+		err    error
+	)
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	switch {
+	case path == scimUsersEndpoint:
+		switch r.Method {
+		case "GET":
+			status, body, err = h.listUsers(r)
+		case "POST":
+			status, body, err = h.createUser(r)
+		default:
+			err = newSCIMError(http.StatusMethodNotAllowed, "", "method %s is not allowed", r.Method)
+		}
+	case strings.HasPrefix(path, scimUsersEndpoint+"/"):
+		id := strings.TrimPrefix(path, scimUsersEndpoint+"/")
+		switch r.Method {
+		case "GET":
+			status, body, err = h.getUser(id)
+		case "PATCH":
+			status, body, err = h.patchUser(id, r)
+		case "DELETE":
+			status, body, err = h.deleteUser(id)
+		default:
+			err = newSCIMError(http.StatusNotImplemented, "", "method %s is not supported for users", r.Method)
+		}
+	case path == scimGroupsEndpoint:
+		switch r.Method {
+		case "GET":
+			status, body, err = h.listGroups(r)
+		case "POST":
+			status, body, err = h.createGroup(r)
+		default:
+			err = newSCIMError(http.StatusNotImplemented, "", "method %s is not supported for groups", r.Method)
+		}
+	case strings.HasPrefix(path, scimGroupsEndpoint+"/"):
+		id := strings.TrimPrefix(path, scimGroupsEndpoint+"/")
+		switch r.Method {
+		case "GET":
+			status, body, err = h.getGroup(id)
+		case "PATCH":
+			status, body, err = h.patchGroup(id, r)
+		case "DELETE":
+			status, body, err = h.deleteGroup(id)
+		default:
+			err = newSCIMError(http.StatusNotImplemented, "", "method %s is not supported for groups", r.Method)
+		}
+	default:
+		err = newSCIMError(http.StatusNotFound, "", "resource %s not found", r.URL.Path)
+	}
+
+	if err != nil {
+		scimErr, ok := err.(*scimError)
+		if !ok {
+			scimErr = newSCIMError(scimStatus(err), "", "%s", err.Error())
+		}
+		status, body = scimErr.status, scimErr
+	}
+
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	if body != nil {
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
+
+// listUsers handles GET /Users.
+func (h *scimHandler) listUsers(r *http.Request) (int, interface{}, error) {
+	var users []User
+
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		attribute, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !strings.EqualFold(attribute, "userName") {
+			return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidFilter, "filtering on %s is not supported", attribute)
+		}
+
+		found, err := h.api.ListUsersByEmail(value, false, false, true)
+		if err != nil && !isNotFound(err) {
+			return 0, nil, err
+		}
+		for _, user := range found.Users {
+			if user.AccountID == "" || user.AccountID == h.api.AccountID {
+				users = append(users, user)
+			}
+		}
+	} else {
+		found, err := h.api.ListUsers(false, false, true, "")
+		if err != nil {
+			return 0, nil, err
+		}
+		users = found.Users
+	}
+
+	roleNames, err := h.roleNames()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	resources := make([]interface{}, len(users))
+	for i, user := range users {
+		resources[i] = newSCIMUser(user, roleNames)
+	}
+
+	list, err := newSCIMListResponse(r, resources)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, list, nil
+}
+
+// getUser handles GET /Users/{id}.
+func (h *scimHandler) getUser(id string) (int, interface{}, error) {
+	user, err := h.api.GetUserDetails(id, false, false, true)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	roleNames, err := h.roleNames()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, newSCIMUser(user, roleNames), nil
+}
+
+// createUser handles POST /Users.
+func (h *scimHandler) createUser(r *http.Request) (int, interface{}, error) {
+	var scimUser SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&scimUser); err != nil {
+		return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidSyntax, "invalid user: %v", err)
+	}
+
+	request := CreateUserRequest{
+		Name:        scimUser.name(),
+		Email:       scimUser.email(),
+		Password:    scimUser.Password,
+		Active:      scimUser.Active,
+		MobilePhone: scimUser.phone(),
+	}
+	if request.Email == "" {
+		return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidValue, "userName or emails must be set")
+	}
+	if request.Name == "" {
+		request.Name = request.Email
+	}
+
+	existing, err := h.api.ListUsersByEmail(request.Email, false, false, false)
+	if err != nil && !isNotFound(err) {
+		return 0, nil, err
+	}
+	for _, user := range existing.Users {
+		if user.AccountID == "" || user.AccountID == h.api.AccountID {
+			return 0, nil, newSCIMError(http.StatusConflict, scimUniqueness, "user %s already exists", request.Email)
+		}
+	}
+
+	user, err := h.api.CreateUser(request, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	// Inactive is the zero value of CreateUserRequest.Active, so it is not sent on creation.
+	if !scimUser.Active {
+		if user, err = h.api.DeactivateUser(user.ID); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return http.StatusCreated, newSCIMUser(user, nil), nil
+}
+
+// patchUser handles PATCH /Users/{id}.
+func (h *scimHandler) patchUser(id string, r *http.Request) (int, interface{}, error) {
+	operations, err := decodeSCIMPatch(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var request UpdateUserRequest
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" {
+			return 0, nil, newSCIMError(http.StatusBadRequest, scimMutability, "operation %s is not supported for users", operation.Op)
+		}
+
+		values := map[string]json.RawMessage{}
+		if operation.Path == "" {
+			if err := json.Unmarshal(operation.Value, &values); err != nil {
+				return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidValue, "invalid patch value: %v", err)
+			}
+		} else {
+			values[operation.Path] = operation.Value
+		}
+
+		// Attributes are applied in a fixed order so that `userName` consistently takes precedence
+		// over `emails` when a value sets both.
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Slice(paths, func(i, j int) bool { return strings.ToLower(paths[i]) < strings.ToLower(paths[j]) })
+
+		for _, path := range paths {
+			value := values[path]
+			switch strings.ToLower(path) {
+			case "active":
+				var active bool
+				if active, err = parseSCIMBool(value); err != nil {
+					return 0, nil, err
+				}
+				request.Active = Bool(active)
+			case "username", "emails[type eq \"work\"].value":
+				err = json.Unmarshal(value, &request.Email)
+			case "displayname", "name.formatted":
+				err = json.Unmarshal(value, &request.Name)
+			case "name":
+				var name SCIMName
+				err = json.Unmarshal(value, &name)
+				request.Name = String((&SCIMUser{Name: &name}).name())
+			case "emails":
+				var emails []SCIMMultiValued
+				err = json.Unmarshal(value, &emails)
+				request.Email = String((&SCIMUser{Emails: emails}).email())
+			case "phonenumbers":
+				var phoneNumbers []SCIMMultiValued
+				err = json.Unmarshal(value, &phoneNumbers)
+				request.MobilePhone = String((&SCIMUser{PhoneNumbers: phoneNumbers}).phone())
+			default:
+				return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidPath, "attribute %s cannot be patched", path)
+			}
+			if err != nil {
+				return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidValue, "invalid value for %s: %v", path, err)
+			}
+		}
+	}
+
+	if request != (UpdateUserRequest{}) {
+		if _, err := h.api.UpdateUserDetails(id, request, false); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return h.getUser(id)
+}
+
+// deleteUser handles DELETE /Users/{id}. DeleteUser succeeds for unknown users, so the user is
+// looked up first to report them as not found.
+func (h *scimHandler) deleteUser(id string) (int, interface{}, error) {
+	if _, err := h.api.GetUserDetails(id, false, false, false); err != nil {
+		return 0, nil, err
+	}
+	if _, err := h.api.DeleteUser(id); err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// listGroups handles GET /Groups.
+func (h *scimHandler) listGroups(r *http.Request) (int, interface{}, error) {
+	var displayName string
+	if filter := r.URL.Query().Get("filter"); filter != "" {
+		attribute, value, err := parseSCIMFilter(filter)
+		if err != nil {
+			return 0, nil, err
+		}
+		if !strings.EqualFold(attribute, "displayName") {
+			return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidFilter, "filtering on %s is not supported", attribute)
+		}
+		displayName = value
+	}
+
+	roles, err := h.api.ListRoles()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	resources := []interface{}{}
+	for _, role := range roles.Roles {
+		if displayName == "" || strings.EqualFold(role.Name, displayName) {
+			resources = append(resources, newSCIMGroup(role, nil))
+		}
+	}
+
+	list, err := newSCIMListResponse(r, resources)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, list, nil
+}
+
+// getGroup handles GET /Groups/{id}.
+func (h *scimHandler) getGroup(id string) (int, interface{}, error) {
+	return h.getGroupWithStatus(id, http.StatusOK)
+}
+
+// getGroupWithStatus returns the group with `id` and its members with `status`.
+func (h *scimHandler) getGroupWithStatus(id string, status int) (int, interface{}, error) {
+	role, err := h.api.GetRoleDetails(id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	members, err := h.api.ListUsers(false, false, false, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return status, newSCIMGroup(role, members.Users), nil
+}
+
+// createGroup handles POST /Groups, creating a role without permissions and granting it to the
+// group's members.
+func (h *scimHandler) createGroup(r *http.Request) (int, interface{}, error) {
+	var group SCIMGroup
+	if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
+		return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidSyntax, "invalid group: %v", err)
+	}
+	if group.DisplayName == "" {
+		return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidValue, "displayName must be set")
+	}
+
+	roles, err := h.api.ListRoles()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, role := range roles.Roles {
+		if strings.EqualFold(role.Name, group.DisplayName) {
+			return 0, nil, newSCIMError(http.StatusConflict, scimUniqueness, "group %s already exists", group.DisplayName)
+		}
+	}
+
+	role, err := h.api.CreateRole(CreateRoleRequest{Name: group.DisplayName})
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := h.replaceGroupMembers(role.ID, group.Members); err != nil {
+		return 0, nil, err
+	}
+
+	return h.getGroupWithStatus(role.ID, http.StatusCreated)
+}
+
+// deleteGroup handles DELETE /Groups/{id}. The role is looked up first so that unknown groups
+// are reported as not found.
+func (h *scimHandler) deleteGroup(id string) (int, interface{}, error) {
+	if _, err := h.api.GetRoleDetails(id); err != nil {
+		return 0, nil, err
+	}
+	if _, err := h.api.DeleteRole(id); err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, nil
+}
+
+// patchGroup handles PATCH /Groups/{id}, granting or revoking the role for added or removed
+// members.
+func (h *scimHandler) patchGroup(id string, r *http.Request) (int, interface{}, error) {
+	operations, err := decodeSCIMPatch(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if _, err := h.api.GetRoleDetails(id); err != nil {
+		return 0, nil, err
+	}
+
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		path := operation.Path
+
+		// A remove with a filtered path, such as `members[value eq "id"]`, names a single member.
+		if op == "remove" && strings.HasPrefix(strings.ToLower(path), "members[") && strings.HasSuffix(path, "]") {
+			attribute, value, err := parseSCIMFilter(path[len("members[") : len(path)-1])
+			if err != nil {
+				return 0, nil, err
+			}
+			if !strings.EqualFold(attribute, "value") {
+				return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidPath, "unsupported member filter %s", path)
+			}
+			if _, err := h.api.RevokeUserRole(value, id); err != nil {
+				return 0, nil, err
+			}
+			continue
+		}
+
+		if !strings.EqualFold(path, "members") {
+			return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidPath, "attribute %s cannot be patched", path)
+		}
+
+		var members []SCIMReference
+		if len(operation.Value) > 0 {
+			if err := json.Unmarshal(operation.Value, &members); err != nil {
+				return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidValue, "invalid members: %v", err)
+			}
+		}
+
+		switch op {
+		case "add":
+			for _, member := range members {
+				if _, err := h.api.GrantUserRole(member.Value, id); err != nil {
+					return 0, nil, err
+				}
+			}
+		case "remove":
+			if len(members) == 0 {
+				members, err = h.groupMembers(id)
+				if err != nil {
+					return 0, nil, err
+				}
+			}
+			for _, member := range members {
+				if _, err := h.api.RevokeUserRole(member.Value, id); err != nil {
+					return 0, nil, err
+				}
+			}
+		case "replace":
+			if err := h.replaceGroupMembers(id, members); err != nil {
+				return 0, nil, err
+			}
+		default:
+			return 0, nil, newSCIMError(http.StatusBadRequest, scimInvalidSyntax, "unknown operation %s", operation.Op)
+		}
+	}
+
+	return h.getGroup(id)
+}
+
+// replaceGroupMembers grants the role to every member in `members` that does not hold it and
+// revokes it from every current member not in `members`.
+func (h *scimHandler) replaceGroupMembers(id string, members []SCIMReference) error {
+	current, err := h.groupMembers(id)
+	if err != nil {
+		return err
+	}
+
+	desired := map[string]bool{}
+	for _, member := range members {
+		desired[member.Value] = true
+	}
+
+	held := map[string]bool{}
+	for _, member := range current {
+		held[member.Value] = true
+		if !desired[member.Value] {
+			if _, err := h.api.RevokeUserRole(member.Value, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, member := range members {
+		if !held[member.Value] {
+			if _, err := h.api.GrantUserRole(member.Value, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// groupMembers lists the members of the group with `id`.
+func (h *scimHandler) groupMembers(id string) ([]SCIMReference, error) {
+	users, err := h.api.ListUsers(false, false, false, id)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]SCIMReference, len(users.Users))
+	for i, user := range users.Users {
+		members[i] = SCIMReference{Value: user.ID}
+	}
+
+	return members, nil
+}
+
+// roleNames maps the account's role IDs to their names.
+func (h *scimHandler) roleNames() (map[string]string, error) {
+	roles, err := h.api.ListRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	for _, role := range roles.Roles {
+		names[role.ID] = role.Name
+	}
+
+	return names, nil
+}
+
+// newSCIMUser converts an AIMS user to a SCIM user. `roleNames` is used to name the user's
+// groups and may be nil.
+func newSCIMUser(user User, roleNames map[string]string) SCIMUser {
+	scimUser := SCIMUser{
+		Schemas:     []string{scimUserSchema},
+		ID:          user.ID,
+		UserName:    user.Email,
+		Name:        &SCIMName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Active:      user.Active,
+		Meta:        newSCIMMeta("User", user.Created, user.Modified, user.Version),
+	}
+
+	if user.Email != "" {
+		scimUser.Emails = []SCIMMultiValued{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	if user.MobilePhone != nil && *user.MobilePhone != "" {
+		scimUser.PhoneNumbers = []SCIMMultiValued{{Value: *user.MobilePhone, Type: "mobile"}}
+	}
+	if user.RoleIds != nil {
+		for _, roleId := range *user.RoleIds {
+			scimUser.Groups = append(scimUser.Groups, SCIMReference{
+				Value:   roleId,
+				Display: roleNames[roleId],
+				Ref:     scimGroupsEndpoint + "/" + roleId,
+			})
+		}
+	}
+
+	return scimUser
+}
+
+// newSCIMGroup converts an AIMS role and the users holding it to a SCIM group.
+func newSCIMGroup(role Role, members []User) SCIMGroup {
+	group := SCIMGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          role.ID,
+		DisplayName: role.Name,
+		Meta:        newSCIMMeta("Group", role.Created, role.Modified, role.Version),
+	}
+
+	for _, member := range members {
+		group.Members = append(group.Members, SCIMReference{
+			Value:   member.ID,
+			Display: member.Email,
+			Ref:     scimUsersEndpoint + "/" + member.ID,
+		})
+	}
+
+	return group
+}
+
+// newSCIMMeta creates SCIM resource metadata.
+func newSCIMMeta(resourceType string, created ModifiedCreated, modified ModifiedCreated, version int64) *SCIMMeta {
+	meta := &SCIMMeta{ResourceType: resourceType}
+
+	if !created.Time().IsZero() {
+		meta.Created = created.Time().UTC().Format(time.RFC3339)
+	}
+	if !modified.Time().IsZero() {
+		meta.LastModified = modified.Time().UTC().Format(time.RFC3339)
+	}
+	if version != 0 {
+		meta.Version = fmt.Sprintf(`W/"%d"`, version)
+	}
+
+	return meta
+}
+
+// newSCIMListResponse pages `resources` using the request's `startIndex` and `count` parameters.
+func newSCIMListResponse(r *http.Request, resources []interface{}) (scimListResponse, error) {
+	startIndex, err := scimQueryInt(r, "startIndex", 1)
+	if err != nil {
+		return scimListResponse{}, err
+	}
+	count, err := scimQueryInt(r, "count", scimDefaultPageSize)
+	if err != nil {
+		return scimListResponse{}, err
+	}
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	page := []interface{}{}
+	if startIndex <= len(resources) {
+		end := startIndex - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+		page = resources[startIndex-1 : end]
+	}
+
+	return scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}, nil
+}
+
+// scimQueryInt parses an integer query parameter, returning `fallback` when it is not set.
+func scimQueryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, newSCIMError(http.StatusBadRequest, scimInvalidValue, "invalid %s %q", name, value)
+	}
+
+	return n, nil
+}
+
+// parseSCIMFilter parses a filter of the form `attribute eq "value"`.
+func parseSCIMFilter(filter string) (string, string, error) {
+	matches := scimFilterRegexp.FindStringSubmatch(filter)
+	if matches == nil {
+		return "", "", newSCIMError(http.StatusBadRequest, scimInvalidFilter, "unsupported filter %q", filter)
+	}
+
+	value, err := strconv.Unquote(`"` + matches[2] + `"`)
+	if err != nil {
+		return "", "", newSCIMError(http.StatusBadRequest, scimInvalidFilter, "invalid filter value in %q", filter)
+	}
+
+	return matches[1], value, nil
+}
+
+// parseSCIMBool parses a boolean patch value. Some identity providers send booleans as strings.
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+
+	return false, newSCIMError(http.StatusBadRequest, scimInvalidValue, "invalid boolean %s", value)
+}
+
+// decodeSCIMPatch decodes the operations of a SCIM PATCH request.
+func decodeSCIMPatch(r *http.Request) ([]scimPatchOperation, error) {
+	var patch scimPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		return nil, newSCIMError(http.StatusBadRequest, scimInvalidSyntax, "invalid patch request: %v", err)
+	}
+
+	for _, schema := range patch.Schemas {
+		if schema == scimPatchOpSchema {
+			return patch.Operations, nil
+		}
+	}
+
+	return nil, newSCIMError(http.StatusBadRequest, scimInvalidSyntax, "patch request must use the %s schema", scimPatchOpSchema)
+}
+
+// scimStatus maps an error from the API to the SCIM response status.
+func scimStatus(err error) int {
+	switch StatusCode(err) {
+	case http.StatusNotFound:
+		return http.StatusNotFound
+	case http.StatusBadRequest:
+		return http.StatusBadRequest
+	case http.StatusConflict:
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// name returns the user's full name from `displayName` or `name`.
+func (u *SCIMUser) name() string {
+	switch {
+	case u.DisplayName != "":
+		return u.DisplayName
+	case u.Name == nil:
+		return ""
+	case u.Name.Formatted != "":
+		return u.Name.Formatted
+	}
+
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// email returns the user's primary email address, falling back to the first email address and
+// then `userName`.
+func (u *SCIMUser) email() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+
+	return u.UserName
+}
+
+// phone returns the user's first phone number.
+func (u *SCIMUser) phone() string {
+	if len(u.PhoneNumbers) > 0 {
+		return u.PhoneNumbers[0].Value
+	}
+
+	return ""
+}
@@ -0,0 +1,528 @@
+package alertlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scimRequest serves a single SCIM request and decodes the response into `v`, if given.
+func scimRequest(t *testing.T, method string, path string, body string, v interface{}) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	NewSCIMHandler(client).ServeHTTP(w, r)
+
+	assert.Equal(t, scimContentType, w.Header().Get("Content-Type"))
+	if v != nil {
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), v))
+	}
+
+	return w
+}
+
+// scimRolesHandler serves the account's roles.
+func scimRolesHandler() {
+	mux.HandleFunc(listRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"roles": [{"id": %q, "name": "Read Only"}, {"id": "R2", "name": "Power User"}]}`, testRoleId)
+	})
+}
+
+func TestSCIM_ListUsers(t *testing.T) {
+	setup()
+	defer teardown()
+
+	scimRolesHandler()
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("include_role_ids"))
+		fmt.Fprintf(w, `{"users": [
+			{"id": %q, "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "active": true, "role_ids": [%q], "version": 2},
+			{"id": "U2", "name": "Lucille Bluth", "email": "lucille@bluth.com"},
+			{"id": "U3", "name": "Gob Bluth", "email": "gob@bluth.com"}
+		]}`, testUserId, testRoleId)
+	})
+
+	var list struct {
+		TotalResults int
+		StartIndex   int
+		ItemsPerPage int
+		Resources    []SCIMUser
+	}
+	w := scimRequest(t, "GET", "/Users?startIndex=2&count=1", "", &list)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, list.TotalResults)
+	assert.Equal(t, 2, list.StartIndex)
+	assert.Equal(t, 1, list.ItemsPerPage)
+	if assert.Len(t, list.Resources, 1) {
+		assert.Equal(t, "lucille@bluth.com", list.Resources[0].UserName)
+		assert.False(t, list.Resources[0].Active)
+	}
+
+	w = scimRequest(t, "GET", "/Users", "", &list)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, list.Resources, 3) {
+		assert.Equal(t, SCIMUser{
+			Schemas:     []string{scimUserSchema},
+			ID:          testUserId,
+			UserName:    testEmail,
+			Name:        &SCIMName{Formatted: testUserFullName},
+			DisplayName: testUserFullName,
+			Emails:      []SCIMMultiValued{{Value: testEmail, Type: "work", Primary: true}},
+			Active:      true,
+			Groups:      []SCIMReference{{Value: testRoleId, Display: "Read Only", Ref: "/Groups/" + testRoleId}},
+			Meta:        &SCIMMeta{ResourceType: "User", Version: `W/"2"`},
+		}, list.Resources[0])
+	}
+}
+
+func TestSCIM_ListUsersFilter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	scimRolesHandler()
+	mux.HandleFunc(listUsersByEmailPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"users": [
+			{"id": %q, "account_id": %q, "email": "bob@bobloblawlaw.com"},
+			{"id": "OTHER", "account_id": %q, "email": "bob@bobloblawlaw.com"}
+		]}`, testUserId, testAccountId, testRelatedAccountId)
+	})
+
+	var list struct {
+		TotalResults int
+		Resources    []SCIMUser
+	}
+	w := scimRequest(t, "GET", `/Users?filter=userName+eq+%22bob@bobloblawlaw.com%22`, "", &list)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, list.TotalResults)
+	if assert.Len(t, list.Resources, 1) {
+		assert.Equal(t, testUserId, list.Resources[0].ID)
+	}
+
+	var scimErr scimError
+	w = scimRequest(t, "GET", `/Users?filter=displayName+sw+%22Bob%22`, "", &scimErr)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, scimInvalidFilter, scimErr.ScimType)
+	assert.Equal(t, "400", scimErr.Status)
+}
+
+func TestSCIM_ListUsersFilterUnknownEmail(t *testing.T) {
+	setup()
+	defer teardown()
+
+	scimRolesHandler()
+	mux.HandleFunc(fmt.Sprintf("/%s/users/email/", aimsServicePath), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	var list struct {
+		TotalResults int
+		Resources    []SCIMUser
+	}
+	w := scimRequest(t, "GET", `/Users?filter=userName+eq+%22lucille@bluth.com%22`, "", &list)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, list.TotalResults)
+	assert.Empty(t, list.Resources)
+}
+
+func TestSCIM_GetUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	scimRolesHandler()
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q, "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "mobile_phone": "123-555-0123", "active": true}`, testUserId)
+	})
+
+	var user SCIMUser
+	w := scimRequest(t, "GET", "/Users/"+testUserId, "", &user)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, testEmail, user.UserName)
+	assert.Equal(t, []SCIMMultiValued{{Value: "123-555-0123", Type: "mobile"}}, user.PhoneNumbers)
+
+	var scimErr scimError
+	w = scimRequest(t, "GET", "/Users/missing", "", &scimErr)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404", scimErr.Status)
+}
+
+func TestSCIM_CreateUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/%s/users/email/", aimsServicePath), func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, testEmail) {
+			fmt.Fprintf(w, `{"users": [{"id": %q, "account_id": %q, "email": "bob@bobloblawlaw.com"}]}`, testUserId, testAccountId)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc(createUserPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		var request CreateUserRequest
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		assert.Equal(t, CreateUserRequest{Name: "Lucille Bluth", Email: "lucille@bluth.com", Active: true, MobilePhone: "123-555-0123"}, request)
+
+		fmt.Fprintf(w, `{"id": "NEW", "name": %q, "email": %q, "active": true}`, request.Name, request.Email)
+	})
+
+	var user SCIMUser
+	w := scimRequest(t, "POST", "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "lucille",
+		"name": {"givenName": "Lucille", "familyName": "Bluth"},
+		"emails": [{"value": "lucille@bluth.com", "primary": true}],
+		"phoneNumbers": [{"value": "123-555-0123"}],
+		"active": true
+	}`, &user)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "NEW", user.ID)
+	assert.Equal(t, "lucille@bluth.com", user.UserName)
+
+	var scimErr scimError
+	w = scimRequest(t, "POST", "/Users", `{"userName": "bob@bobloblawlaw.com"}`, &scimErr)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, scimUniqueness, scimErr.ScimType)
+
+	w = scimRequest(t, "POST", "/Users", `{}`, &scimErr)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, scimInvalidValue, scimErr.ScimType)
+}
+
+func TestSCIM_CreateInactiveUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(fmt.Sprintf("/%s/users/email/", aimsServicePath), func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc(createUserPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q, "name": "Buster Bluth", "email": "buster@bluth.com", "active": true}`, testUserId)
+	})
+	updates := []string{}
+	mux.HandleFunc(updateUserPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method, "Expected method 'POST', got %s", r.Method)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		updates = append(updates, strings.TrimSpace(string(body)))
+		fmt.Fprintf(w, `{"id": %q, "name": "Buster Bluth", "email": "buster@bluth.com", "active": false}`, testUserId)
+	})
+
+	var user SCIMUser
+	w := scimRequest(t, "POST", "/Users", `{"userName": "buster@bluth.com", "active": false}`, &user)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.False(t, user.Active)
+	assert.Equal(t, []string{`{"active":false}`}, updates)
+}
+
+func TestSCIM_PatchUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	scimRolesHandler()
+	updates := []map[string]interface{}{}
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var update map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			updates = append(updates, update)
+		}
+		fmt.Fprintf(w, `{"id": %q, "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "mobile_phone": "123-555-0123", "active": true}`, testUserId)
+	})
+
+	w := scimRequest(t, "PATCH", "/Users/"+testUserId, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "replace", "path": "displayName", "value": "Robert Loblaw"},
+			{"op": "Replace", "value": {"active": "False"}}
+		]
+	}`, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "Robert Loblaw", "active": false},
+	}, updates)
+
+	var scimErr scimError
+	w = scimRequest(t, "PATCH", "/Users/"+testUserId, `{"Operations": []}`, &scimErr)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, scimInvalidSyntax, scimErr.ScimType)
+
+	w = scimRequest(t, "PATCH", "/Users/"+testUserId, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [{"op": "replace", "path": "title", "value": "Lawyer"}]
+	}`, &scimErr)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, scimInvalidPath, scimErr.ScimType)
+}
+
+func TestSCIM_PatchUserNameAndEmails(t *testing.T) {
+	setup()
+	defer teardown()
+
+	scimRolesHandler()
+	emails := []interface{}{}
+	mux.HandleFunc(getUserDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var update map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&update)
+			emails = append(emails, update["email"])
+		}
+		fmt.Fprintf(w, `{"id": %q, "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com", "active": true}`, testUserId)
+	})
+
+	for i := 0; i < 10; i++ {
+		w := scimRequest(t, "PATCH", "/Users/"+testUserId, `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [{"op": "replace", "value": {
+				"userName": "robert@bobloblawlaw.com",
+				"emails": [{"value": "bob@loblaw.com", "primary": true}]
+			}}]
+		}`, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	for _, email := range emails {
+		assert.Equal(t, "robert@bobloblawlaw.com", email)
+	}
+}
+
+func TestSCIM_DeleteUser(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deleted := []string{}
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/users/", aimsServicePath, testAccountId), func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path != getUserDetailsPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %q, "name": "Bob Loblaw", "email": "bob@bobloblawlaw.com"}`, testUserId)
+	})
+
+	w := scimRequest(t, "DELETE", "/Users/"+testUserId, "", nil)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, []string{deleteUserPath}, deleted)
+	body, _ := ioutil.ReadAll(w.Body)
+	assert.Empty(t, body)
+
+	var scimErr scimError
+	w = scimRequest(t, "DELETE", "/Users/missing", "", &scimErr)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404", scimErr.Status)
+	assert.Len(t, deleted, 1)
+}
+
+func TestSCIM_ListGroups(t *testing.T) {
+	setup()
+	defer teardown()
+
+	scimRolesHandler()
+
+	var list struct {
+		TotalResults int
+		Resources    []SCIMGroup
+	}
+	w := scimRequest(t, "GET", `/Groups?filter=displayName+eq+%22power+user%22`, "", &list)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, list.TotalResults)
+	if assert.Len(t, list.Resources, 1) {
+		assert.Equal(t, "R2", list.Resources[0].ID)
+		assert.Equal(t, "Power User", list.Resources[0].DisplayName)
+	}
+}
+
+func TestSCIM_GetGroup(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc(getRoleDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q, "name": "Read Only"}`, testRoleId)
+	})
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, testRoleId, r.URL.Query().Get("role_id"))
+		fmt.Fprintf(w, `{"users": [{"id": %q, "email": "bob@bobloblawlaw.com"}]}`, testUserId)
+	})
+
+	var group SCIMGroup
+	w := scimRequest(t, "GET", "/Groups/"+testRoleId, "", &group)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, SCIMGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          testRoleId,
+		DisplayName: "Read Only",
+		Members:     []SCIMReference{{Value: testUserId, Display: testEmail, Ref: "/Users/" + testUserId}},
+		Meta:        &SCIMMeta{ResourceType: "Group"},
+	}, group)
+}
+
+func TestSCIM_CreateGroup(t *testing.T) {
+	setup()
+	defer teardown()
+
+	granted := false
+	mux.HandleFunc(listRolesPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var request CreateRoleRequest
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			assert.Equal(t, CreateRoleRequest{Name: "Auditors"}, request)
+
+			fmt.Fprintf(w, `{"id": %q, "name": "Auditors"}`, testRoleId)
+			return
+		}
+		fmt.Fprint(w, `{"roles": [{"id": "R2", "name": "Power User"}]}`)
+	})
+	mux.HandleFunc(getRoleDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q, "name": "Auditors"}`, testRoleId)
+	})
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		if !granted {
+			fmt.Fprint(w, `{"users": []}`)
+			return
+		}
+		fmt.Fprintf(w, `{"users": [{"id": %q, "email": "bob@bobloblawlaw.com"}]}`, testUserId)
+	})
+	mux.HandleFunc(grantUserRolePath, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method, "Expected method 'PUT', got %s", r.Method)
+		granted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var group SCIMGroup
+	w := scimRequest(t, "POST", "/Groups", fmt.Sprintf(`{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+		"displayName": "Auditors",
+		"members": [{"value": %q}]
+	}`, testUserId), &group)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.True(t, granted)
+	assert.Equal(t, testRoleId, group.ID)
+	assert.Equal(t, []SCIMReference{{Value: testUserId, Display: testEmail, Ref: "/Users/" + testUserId}}, group.Members)
+
+	var scimErr scimError
+	w = scimRequest(t, "POST", "/Groups", `{"displayName": "power user"}`, &scimErr)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, scimUniqueness, scimErr.ScimType)
+
+	w = scimRequest(t, "POST", "/Groups", `{}`, &scimErr)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, scimInvalidValue, scimErr.ScimType)
+}
+
+func TestSCIM_DeleteGroup(t *testing.T) {
+	setup()
+	defer teardown()
+
+	deleted := false
+	mux.HandleFunc(getRoleDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fmt.Fprintf(w, `{"id": %q, "name": "Read Only"}`, testRoleId)
+	})
+
+	w := scimRequest(t, "DELETE", "/Groups/"+testRoleId, "", nil)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.True(t, deleted)
+
+	var scimErr scimError
+	w = scimRequest(t, "DELETE", "/Groups/missing", "", &scimErr)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404", scimErr.Status)
+}
+
+func TestSCIM_PatchGroup(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := []string{}
+	mux.HandleFunc(getRoleDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %q, "name": "Read Only"}`, testRoleId)
+	})
+	mux.HandleFunc(listUsersPath, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"users": [{"id": "U1"}, {"id": "U2"}]}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/%s/%s/users/", aimsServicePath, testAccountId), func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, fmt.Sprintf("%s %s", r.Method, strings.TrimSuffix(r.URL.Path, "/roles/"+testRoleId)))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	usersPath := fmt.Sprintf("/%s/%s/users", aimsServicePath, testAccountId)
+
+	w := scimRequest(t, "PATCH", "/Groups/"+testRoleId, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": "U3"}]},
+			{"op": "remove", "path": "members[value eq \"U1\"]"},
+			{"op": "replace", "path": "members", "value": [{"value": "U2"}, {"value": "U4"}]}
+		]
+	}`, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{
+		"PUT " + usersPath + "/U3",
+		"DELETE " + usersPath + "/U1",
+		"DELETE " + usersPath + "/U1",
+		"PUT " + usersPath + "/U4",
+	}, requests)
+}
+
+func TestSCIM_Unsupported(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var unsupportedTests = []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"PUT", "/Groups/" + testRoleId, http.StatusNotImplemented},
+		{"PATCH", "/Groups", http.StatusNotImplemented},
+		{"PUT", "/Users/" + testUserId, http.StatusNotImplemented},
+		{"DELETE", "/Users", http.StatusMethodNotAllowed},
+		{"GET", "/Schemas", http.StatusNotFound},
+	}
+
+	for _, tt := range unsupportedTests {
+		var scimErr scimError
+		w := scimRequest(t, tt.method, tt.path, "", &scimErr)
+
+		assert.Equal(t, tt.status, w.Code)
+		assert.Equal(t, []string{scimErrorSchema}, scimErr.Schemas)
+	}
+}